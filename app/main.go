@@ -3,6 +3,7 @@ package main
 import (
 	"go-todo-api/internal"
 	"go-todo-api/internal/config"
+	"go-todo-api/internal/mailer"
 	"go-todo-api/internal/rest/middleware"
 	"go-todo-api/internal/util"
 	"go-todo-api/internal/workers"
@@ -11,6 +12,7 @@ import (
 	"os"
 	"strconv"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gocraft/work"
@@ -41,23 +43,62 @@ func main() {
 	if err != nil {
 		logrus.Fatalf("Failed to initialize mailer config: %v", err)
 	}
+	twoFactor, err := config.InitTwoFactor()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize two-factor config: %v", err)
+	}
+	oidcConfig, err := config.InitOIDC()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize OIDC config: %v", err)
+	}
 	mailerConfig, err := config.InitMailer()
 	if err != nil {
 		logrus.Fatalf("Failed to initialize mailer config: %v", err)
 	}
+	mail, err := mailer.New(mailerConfig)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize mailer: %v", err)
+	}
+	appURL, err := config.InitAppURL()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize app url config: %v", err)
+	}
+	cursorCodec, err := config.InitCursorCodec()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize cursor config: %v", err)
+	}
+	verificationSigner, err := config.InitEmailVerificationSigner()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize email verification config: %v", err)
+	}
 	redisPool, err := config.InitRedis()
 	if err != nil {
 		logrus.Fatalf("Failed to initialize redis pool config: %v", err)
 	}
+	idempotencyTTL, err := config.InitIdempotencyTTL()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize idempotency config: %v", err)
+	}
+	requireIfMatch, err := config.InitRequireIfMatch()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize precondition config: %v", err)
+	}
+	requireVerifiedEmail, err := config.InitRequireVerifiedEmail()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize email verification config: %v", err)
+	}
+	devMailbox, err := config.InitDevMailbox()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize mailbox config: %v", err)
+	}
+	outboxPoll, err := config.InitOutboxPollInterval()
+	if err != nil {
+		logrus.Fatalf("Failed to initialize outbox config: %v", err)
+	}
 
 	workerPool := work.NewWorkerPool(workers.MailWorker{}, 10, "todo_queue", redisPool)
-	mailWorker := workers.NewMailWorker(config.NewLogger(), mailerConfig)
-	workerPool.Job("send_email", mailWorker.SendEmail)
 	enqueuer := work.NewEnqueuer("todo_queue", redisPool)
 
-	workerPool.Start()
-	defer workerPool.Stop()
-
 	r := gin.New()
 
 	timeoutStr := os.Getenv("CONTEXT_TIMEOUT")
@@ -80,14 +121,32 @@ func main() {
 		})
 	})
 
-	internal.Bootstrap(&internal.BootstrapConfig{
-		DB:         db,
-		Log:        config.NewLogger(),
-		Route:      r,
-		JwtService: jwtService,
-		Enqueurer:  enqueuer,
+	outboxRelay := internal.Bootstrap(&internal.BootstrapConfig{
+		DB:             db,
+		Log:            config.NewLogger(),
+		Route:          r,
+		JwtService:     jwtService,
+		TwoFactor:      twoFactor,
+		OIDC:           oidcConfig,
+		Enqueurer:      enqueuer,
+		Cursor:         cursorCodec,
+		Redis:          redisPool,
+		IdempotencyTTL: idempotencyTTL,
+		RequireIfMatch: requireIfMatch,
+		WorkerPool:     workerPool,
+		Mailer:         mail,
+		AppURL:         appURL,
+		Verifier:       verificationSigner,
+		VerifiedOnly:   requireVerifiedEmail,
+		OutboxPoll:     outboxPoll,
+		DevMailbox:     devMailbox,
 	})
 
+	workerPool.Start()
+	defer workerPool.Stop()
+	outboxRelay.Start()
+	defer outboxRelay.Stop()
+
 	address := os.Getenv("SERVER_ADDRESS")
 	if address == "" {
 		address = defaultAddress
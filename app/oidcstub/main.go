@@ -0,0 +1,49 @@
+// Command oidcstub runs a stub OpenID Connect provider for trying single
+// sign-on locally. Point the API at it with
+//
+//	OIDC_ISSUER=http://localhost:9096
+//	OIDC_CLIENT_ID=go-todo-api
+//	OIDC_REDIRECT_URL=http://localhost:8080/v1/users/_sso/callback
+//
+// and open v1/users/_sso/login in a browser.
+package main
+
+import (
+	"go-todo-api/internal/oidc/stub"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultAddress = ":9096"
+	defaultIssuer  = "http://localhost:9096"
+)
+
+func main() {
+	address := os.Getenv("OIDC_STUB_ADDRESS")
+	if address == "" {
+		address = defaultAddress
+	}
+	issuer := os.Getenv("OIDC_STUB_ISSUER")
+	if issuer == "" {
+		issuer = defaultIssuer
+	}
+
+	provider, err := stub.New(issuer)
+	if err != nil {
+		logrus.Fatalf("Failed to initialize stub provider: %v", err)
+	}
+
+	server := &http.Server{
+		Addr:              address,
+		Handler:           provider.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	logrus.Infof("Stub OIDC provider %s listening on %s", issuer, address)
+	if err := server.ListenAndServe(); err != nil {
+		logrus.Fatalf("Stub OIDC provider stopped: %v", err)
+	}
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS fk_custom_role;
+ALTER TABLE users DROP COLUMN IF EXISTS role_id;
+DROP INDEX IF EXISTS role_permissions_role_id_permission_key;
+DROP TABLE IF EXISTS role_permissions;
+DROP INDEX IF EXISTS roles_name_key;
+DROP TABLE IF EXISTS roles;
+
+COMMIT;
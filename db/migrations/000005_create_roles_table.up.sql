@@ -0,0 +1,27 @@
+BEGIN;
+
+CREATE TABLE roles (
+    id SERIAL NOT NULL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP DEFAULT NULL
+);
+
+CREATE UNIQUE INDEX roles_name_key ON roles(name) WHERE deleted_at IS NULL;
+
+CREATE TABLE role_permissions (
+    id SERIAL NOT NULL PRIMARY KEY,
+    role_id INT NOT NULL,
+    permission VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX role_permissions_role_id_permission_key ON role_permissions(role_id, permission);
+
+ALTER TABLE users ADD COLUMN role_id INT DEFAULT NULL;
+ALTER TABLE users ADD CONSTRAINT fk_custom_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE SET NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS tags_search_vector_idx;
+ALTER TABLE tags DROP COLUMN IF EXISTS search_vector;
+DROP INDEX IF EXISTS todos_search_vector_idx;
+ALTER TABLE todos DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
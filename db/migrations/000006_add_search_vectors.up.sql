@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE todos ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'B')
+) STORED;
+
+CREATE INDEX todos_search_vector_idx ON todos USING GIN (search_vector);
+
+ALTER TABLE tags ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('english', coalesce(name, ''))
+) STORED;
+
+CREATE INDEX tags_search_vector_idx ON tags USING GIN (search_vector);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE tags DROP COLUMN IF EXISTS version;
+ALTER TABLE todos DROP COLUMN IF EXISTS version;
+
+COMMIT;
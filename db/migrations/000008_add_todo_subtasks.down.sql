@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS todos_parent_id_position_idx;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS fk_parent;
+ALTER TABLE todos DROP COLUMN IF EXISTS position;
+ALTER TABLE todos DROP COLUMN IF EXISTS parent_id;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE todos ADD COLUMN parent_id INTEGER;
+ALTER TABLE todos ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE todos ADD CONSTRAINT fk_parent FOREIGN KEY (parent_id) REFERENCES todos(id) ON DELETE CASCADE;
+
+CREATE INDEX todos_parent_id_position_idx ON todos(parent_id, position) WHERE deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP INDEX IF EXISTS todos_recurring_idx;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS fk_next_occurrence;
+ALTER TABLE todos DROP COLUMN IF EXISTS next_occurrence_id;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence_start;
+ALTER TABLE todos DROP COLUMN IF EXISTS recurrence;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE todos ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE todos ADD COLUMN recurrence_start TIMESTAMP DEFAULT NULL;
+ALTER TABLE todos ADD COLUMN next_occurrence_id INTEGER DEFAULT NULL;
+
+ALTER TABLE todos ADD CONSTRAINT fk_next_occurrence FOREIGN KEY (next_occurrence_id) REFERENCES todos(id) ON DELETE SET NULL;
+
+CREATE INDEX todos_recurring_idx ON todos(user_id) WHERE recurrence <> '' AND deleted_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE todos DROP COLUMN IF EXISTS overdue_notified_at;
+
+DROP TABLE IF EXISTS todo_reminders;
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE todo_reminders (
+    id SERIAL NOT NULL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    todo_id INT NOT NULL,
+    minutes_before INT NOT NULL,
+    remind_at TIMESTAMP NOT NULL,
+    sent_at TIMESTAMP DEFAULT NULL,
+    job_id VARCHAR(64) NOT NULL DEFAULT '',
+    scheduled_for BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_todo FOREIGN KEY (todo_id) REFERENCES todos(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX todo_reminders_todo_id_minutes_before_key ON todo_reminders(todo_id, minutes_before);
+
+CREATE INDEX todo_reminders_pending_idx ON todo_reminders(remind_at) WHERE sent_at IS NULL;
+
+ALTER TABLE todos ADD COLUMN overdue_notified_at TIMESTAMP DEFAULT NULL;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE user_preferences (
+    user_id INT NOT NULL PRIMARY KEY,
+    digest_frequency VARCHAR(10) NOT NULL DEFAULT 'off',
+    digest_hour SMALLINT NOT NULL DEFAULT 8,
+    digest_weekday SMALLINT NOT NULL DEFAULT 1,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    last_digest_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT user_preferences_digest_frequency_check CHECK (digest_frequency IN ('off', 'daily', 'weekly')),
+    CONSTRAINT user_preferences_digest_hour_check CHECK (digest_hour BETWEEN 0 AND 23),
+    CONSTRAINT user_preferences_digest_weekday_check CHECK (digest_weekday BETWEEN 0 AND 6)
+);
+
+CREATE INDEX user_preferences_digest_idx ON user_preferences(user_id) WHERE digest_frequency <> 'off';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE user_preferences DROP COLUMN IF EXISTS locale;
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE user_preferences ADD COLUMN locale VARCHAR(10) NOT NULL DEFAULT 'en';
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+CREATE TABLE dead_letters (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    job_id VARCHAR(64) NOT NULL DEFAULT '',
+    payload JSONB NOT NULL DEFAULT '{}',
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    failed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX dead_letters_uuid_key ON dead_letters(uuid);
+
+CREATE INDEX dead_letters_failed_at_idx ON dead_letters(failed_at DESC);
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN;
+
+CREATE TABLE sessions (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id INT NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX sessions_uuid_key ON sessions(uuid);
+
+CREATE INDEX sessions_user_id_idx ON sessions(user_id) WHERE revoked_at IS NULL;
+
+-- Every refresh token a session has been issued. Used tokens are kept while
+-- the session lives so that presenting one again is detected as reuse.
+CREATE TABLE refresh_tokens (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    session_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_session FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX refresh_tokens_token_hash_key ON refresh_tokens(token_hash);
+
+CREATE INDEX refresh_tokens_session_id_idx ON refresh_tokens(session_id);
+
+ALTER TABLE users DROP COLUMN token;
+
+COMMIT;
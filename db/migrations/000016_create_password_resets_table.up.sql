@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE password_resets (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    user_id INT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX password_resets_token_hash_key ON password_resets(token_hash);
+
+CREATE INDEX password_resets_user_id_idx ON password_resets(user_id, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS verification_sent_at,
+    DROP COLUMN IF EXISTS pending_email,
+    DROP COLUMN IF EXISTS verified_at;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE users
+    ADD COLUMN verified_at TIMESTAMP DEFAULT NULL,
+    ADD COLUMN pending_email VARCHAR(255) DEFAULT NULL,
+    ADD COLUMN verification_sent_at TIMESTAMP DEFAULT NULL;
+
+-- Accounts created before verification existed are trusted as they are.
+UPDATE users SET verified_at = created_at;
+
+COMMIT;
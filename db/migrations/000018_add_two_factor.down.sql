@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP TABLE IF EXISTS two_factor_policies;
+
+DROP TABLE IF EXISTS login_challenges;
+
+DROP TABLE IF EXISTS recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
+
+COMMIT;
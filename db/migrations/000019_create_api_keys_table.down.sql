@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS api_key_scopes;
+
+DROP TABLE IF EXISTS api_keys;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+CREATE TABLE api_keys (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    uuid UUID NOT NULL DEFAULT gen_random_uuid(),
+    user_id INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    secret_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMP DEFAULT NULL,
+    last_used_at TIMESTAMP DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX api_keys_prefix_key ON api_keys(prefix);
+
+CREATE UNIQUE INDEX api_keys_uuid_key ON api_keys(uuid);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys(user_id);
+
+CREATE TABLE api_key_scopes (
+    id BIGSERIAL NOT NULL PRIMARY KEY,
+    api_key_id BIGINT NOT NULL,
+    scope VARCHAR(100) NOT NULL,
+    CONSTRAINT fk_api_key FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX api_key_scopes_api_key_id_scope_key ON api_key_scopes(api_key_id, scope);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS processed_at;
+
+COMMIT;
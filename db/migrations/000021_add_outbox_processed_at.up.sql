@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE outbox
+    ADD COLUMN processed_at TIMESTAMP DEFAULT NULL;
+
+COMMIT;
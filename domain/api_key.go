@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyResponse describes a key; Key, the full secret, is only returned
+// when the key is created.
+type APIKeyResponse struct {
+	UUID       uuid.UUID  `json:"uuid"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	Key        string     `json:"key,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreateRequest lists the permissions the key may use as Scopes. A key
+// without ExpiresAt does not expire.
+type APIKeyCreateRequest struct {
+	GetUserId
+	Name      string     `json:"name" validate:"required,max=100"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyListRequest struct {
+	GetUserId
+}
+
+type APIKeyRevokeRequest struct {
+	GetUserId
+	UUID uuid.UUID `json:"uuid"`
+}
@@ -0,0 +1,23 @@
+package converter
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+)
+
+func APIKeyToResponse(key *entity.APIKey) *domain.APIKeyResponse {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.Scope)
+	}
+
+	return &domain.APIKeyResponse{
+		UUID:       key.UUID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
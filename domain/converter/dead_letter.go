@@ -0,0 +1,19 @@
+package converter
+
+import (
+	"encoding/json"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+)
+
+func DeadLetterToResponse(deadLetter *entity.DeadLetter) *domain.DeadLetterResponse {
+	return &domain.DeadLetterResponse{
+		UUID:      deadLetter.UUID,
+		JobName:   deadLetter.JobName,
+		JobID:     deadLetter.JobID,
+		Payload:   json.RawMessage(deadLetter.Payload),
+		Attempts:  deadLetter.Attempts,
+		LastError: deadLetter.LastError,
+		FailedAt:  deadLetter.FailedAt,
+	}
+}
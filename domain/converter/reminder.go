@@ -0,0 +1,15 @@
+package converter
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+)
+
+func ReminderToResponse(reminder *entity.TodoReminder) *domain.ReminderResponse {
+	return &domain.ReminderResponse{
+		UUID:          reminder.UUID,
+		MinutesBefore: reminder.MinutesBefore,
+		RemindAt:      reminder.RemindAt,
+		SentAt:        reminder.SentAt,
+	}
+}
@@ -0,0 +1,21 @@
+package converter
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+)
+
+func RoleToResponse(role *entity.Role) *domain.RoleResponse {
+	permissions := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissions = append(permissions, permission.Permission)
+	}
+
+	return &domain.RoleResponse{
+		UUID:        role.UUID,
+		Name:        role.Name,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
@@ -0,0 +1,16 @@
+package converter
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+)
+
+func SearchResultToResponse(result *entity.SearchResult) *domain.SearchResponse {
+	return &domain.SearchResponse{
+		Type:    result.Type,
+		UUID:    result.UUID,
+		Title:   result.Title,
+		Snippet: result.Snippet,
+		Rank:    result.Rank,
+	}
+}
@@ -11,5 +11,6 @@ func TagToResponse(tag *entity.Tag) *domain.TagResponse {
 		Name:      tag.Name,
 		CreatedAt: tag.CreatedAt,
 		UpdatedAt: tag.UpdatedAt,
+		Version:   tag.Version,
 	}
 }
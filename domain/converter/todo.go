@@ -14,6 +14,7 @@ func TodoToResponse(todo *entity.Todo) *domain.TodoResponse {
 				Name:      tag.Name,
 				CreatedAt: tag.CreatedAt,
 				UpdatedAt: tag.UpdatedAt,
+				Version:   tag.Version,
 			})
 		}
 	}
@@ -24,8 +25,11 @@ func TodoToResponse(todo *entity.Todo) *domain.TodoResponse {
 		Description: todo.Description,
 		IsCompleted: todo.IsCompleted,
 		DueTime:     todo.DueTime,
+		Recurrence:  todo.Recurrence,
 		CreatedAt:   todo.CreatedAt,
 		UpdatedAt:   todo.UpdatedAt,
+		Version:     todo.Version,
+		Position:    todo.Position,
 		Tags:        tagResponses,
 	}
 }
@@ -3,21 +3,54 @@ package converter
 import (
 	"go-todo-api/domain"
 	"go-todo-api/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 func UserToResponse(user *entity.User) *domain.UserResponse {
-	return &domain.UserResponse{
-		UUID:      user.UUID,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	response := &domain.UserResponse{
+		UUID:             user.UUID,
+		Name:             user.Name,
+		Email:            user.Email,
+		VerifiedAt:       user.VerifiedAt,
+		CreatedAt:        user.CreatedAt,
+		UpdatedAt:        user.UpdatedAt,
+		TwoFactorEnabled: user.TOTPEnabledAt != nil,
+	}
+	if user.PendingEmail != nil {
+		response.PendingEmail = *user.PendingEmail
 	}
+	return response
 }
 
-func UserToResponseWithToken(user *entity.User, token string) *domain.UserResponse {
+func UserToResponseWithToken(user *entity.User, token string, expiresAt time.Time, refreshToken string) *domain.UserResponse {
 	return &domain.UserResponse{
-		UUID:  user.UUID,
-		Token: token,
+		UUID:         user.UUID,
+		Token:        token,
+		ExpiresAt:    &expiresAt,
+		RefreshToken: refreshToken,
+	}
+}
+
+func SessionToResponse(session *entity.Session, current uuid.UUID) *domain.SessionResponse {
+	return &domain.SessionResponse{
+		UUID:       session.UUID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		Current:    session.UUID == current,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+		CreatedAt:  session.CreatedAt,
+	}
+}
+
+func UserPreferenceToResponse(preference *entity.UserPreference) *domain.UserPreferenceResponse {
+	return &domain.UserPreferenceResponse{
+		DigestFrequency: preference.DigestFrequency,
+		DigestHour:      preference.DigestHour,
+		DigestWeekday:   preference.DigestWeekday,
+		Timezone:        preference.Timezone,
+		Locale:          preference.Locale,
 	}
 }
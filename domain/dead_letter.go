@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeadLetterResponse struct {
+	UUID      uuid.UUID       `json:"uuid"`
+	JobName   string          `json:"job_name"`
+	JobID     string          `json:"job_id"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error"`
+	FailedAt  time.Time       `json:"failed_at"`
+}
+
+type DeadLetterListRequest struct {
+	Page    int    `json:"page"`
+	Size    int    `json:"size"`
+	JobName string `json:"job_name"`
+}
+
+type DeadLetterRequest struct {
+	UUID uuid.UUID `json:"uuid"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+type EmailPreviewRequest struct {
+	Kind   string
+	Locale string
+}
+
+type EmailPreviewResponse struct {
+	Kind    string `json:"kind"`
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "time"
+
+type MailboxMessageResponse struct {
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	HTML    string    `json:"html"`
+	Text    string    `json:"text"`
+	SentAt  time.Time `json:"sent_at"`
+}
@@ -0,0 +1,15 @@
+package domain
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// CursorPage describes one keyset page. Values holds the sort key values of
+// the row the page starts after (or before, when Backward is set) followed
+// by its id, and is empty for the first page.
+type CursorPage struct {
+	Values   []any
+	Backward bool
+	Limit    int
+}
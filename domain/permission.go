@@ -0,0 +1,44 @@
+package domain
+
+type Permission string
+
+const (
+	PermTodoCreate    Permission = "todo:create"
+	PermTodoReadOwn   Permission = "todo:read:own"
+	PermTodoReadAny   Permission = "todo:read:any"
+	PermTodoUpdateOwn Permission = "todo:update:own"
+	PermTodoUpdateAny Permission = "todo:update:any"
+	PermTodoDeleteOwn Permission = "todo:delete:own"
+	PermTodoDeleteAny Permission = "todo:delete:any"
+	PermTagCreate     Permission = "tag:create"
+	PermTagRead       Permission = "tag:read"
+	PermTagUpdate     Permission = "tag:update"
+	PermTagDelete     Permission = "tag:delete"
+	PermUserRead      Permission = "user:read"
+	PermUserManage    Permission = "user:manage"
+)
+
+var AllPermissions = []Permission{
+	PermTodoCreate,
+	PermTodoReadOwn,
+	PermTodoReadAny,
+	PermTodoUpdateOwn,
+	PermTodoUpdateAny,
+	PermTodoDeleteOwn,
+	PermTodoDeleteAny,
+	PermTagCreate,
+	PermTagRead,
+	PermTagUpdate,
+	PermTagDelete,
+	PermUserRead,
+	PermUserManage,
+}
+
+func IsKnownPermission(permission string) bool {
+	for _, p := range AllPermissions {
+		if string(p) == permission {
+			return true
+		}
+	}
+	return false
+}
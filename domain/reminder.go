@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReminderResponse struct {
+	UUID          uuid.UUID  `json:"uuid"`
+	MinutesBefore int        `json:"minutes_before"`
+	RemindAt      time.Time  `json:"remind_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+}
+
+// ReminderSetRequest replaces every reminder of a todo. An empty list
+// removes them all.
+type ReminderSetRequest struct {
+	TodoID        uint  `json:"-"`
+	MinutesBefore []int `json:"minutes_before" validate:"max=10,dive,min=1,max=43200"`
+	AllUsers      bool  `json:"-"`
+}
+
+type ReminderListRequest struct {
+	TodoID   uint `json:"-"`
+	AllUsers bool `json:"-"`
+}
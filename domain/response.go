@@ -6,10 +6,23 @@ type PaginationMeta struct {
 	PageSize    int   `json:"pageSize"`
 	TotalCount  int64 `json:"totalCount"`
 }
+
+type BulkItemError struct {
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
+
+type CursorMeta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	PrevCursor string `json:"prevCursor,omitempty"`
+	PageSize   int    `json:"pageSize"`
+}
+
 type Response[T any] struct {
 	Status     bool            `json:"status"`
 	StatusCode int             `json:"statusCode"`
 	Message    string          `json:"message"`
 	Data       T               `json:"data,omitempty"`
 	Meta       *PaginationMeta `json:"meta,omitempty"`
+	CursorMeta *CursorMeta     `json:"cursorMeta,omitempty"`
 }
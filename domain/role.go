@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RoleResponse struct {
+	UUID        uuid.UUID `json:"uuid"`
+	Name        string    `json:"name,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+type RoleCreateRequest struct {
+	Name        string   `json:"name" validate:"required,max=100"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required"`
+}
+
+type RoleDeleteRequest struct {
+	ID uint `json:"id"`
+}
+
+type RoleAssignRequest struct {
+	ID     uint `json:"id"`
+	UserID uint `json:"user_id"`
+}
+
+// TwoFactorPolicyResponse lists the built-in roles whose users must enroll
+// in two-factor authentication before they are granted any permission.
+type TwoFactorPolicyResponse struct {
+	Roles []string `json:"roles"`
+}
+
+type TwoFactorPolicyRequest struct {
+	Roles []string `json:"roles" validate:"dive,required"`
+}
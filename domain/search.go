@@ -0,0 +1,26 @@
+package domain
+
+import "github.com/google/uuid"
+
+const (
+	SearchTypeTodo = "todo"
+	SearchTypeTag  = "tag"
+)
+
+// SearchResponse carries a Snippet of the matching text as HTML: the text is
+// escaped and the matched words are wrapped in <mark>.
+type SearchResponse struct {
+	Type    string    `json:"type"`
+	UUID    uuid.UUID `json:"uuid"`
+	Title   string    `json:"title"`
+	Snippet string    `json:"snippet"`
+	Rank    float32   `json:"rank"`
+}
+
+type SearchRequest struct {
+	Query    string `json:"q" validate:"required,max=255"`
+	Type     string `json:"type" validate:"omitempty,oneof=todo tag"`
+	Page     int    `json:"page"`
+	Size     int    `json:"size"`
+	AllUsers bool   `json:"-"`
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SubtaskCreateRequest struct {
+	ParentID    uint       `json:"-"`
+	Title       string     `json:"title" validate:"required,max=255"`
+	Description string     `json:"description"`
+	DueTime     *time.Time `json:"due_time"`
+	AllUsers    bool       `json:"-"`
+}
+
+type SubtaskListRequest struct {
+	ParentID uint `json:"-"`
+	AllUsers bool `json:"-"`
+}
+
+type SubtaskReorderRequest struct {
+	ParentID uint        `json:"-"`
+	Order    []uuid.UUID `json:"order" validate:"required,min=1"`
+	AllUsers bool        `json:"-"`
+}
+
+type SubtaskCompleteRequest struct {
+	ParentID    uint      `json:"-"`
+	SubtaskUUID uuid.UUID `json:"-"`
+	IsCompleted bool      `json:"-"`
+	AllUsers    bool      `json:"-"`
+}
@@ -11,6 +11,7 @@ type TagResponse struct {
 	Name      string    `json:"name,omitempty" validate:"required,max=255"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
 	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	Version   uint      `json:"version,omitempty"`
 }
 
 type TagCreateRequest struct {
@@ -18,14 +19,23 @@ type TagCreateRequest struct {
 }
 
 type TagUpdateRequest struct {
-	ID   uint   `json:"id"`
-	Name string `json:"name,omitempty" validate:"max=255"`
+	ID      uint   `json:"id"`
+	Name    string `json:"name,omitempty" validate:"max=255"`
+	Version *uint  `json:"version,omitempty"`
 }
 
 type TagDeleteRequest struct {
-	ID uint `json:"id"`
+	ID      uint  `json:"id"`
+	Version *uint `json:"-"`
 }
 
 type TagGetDataRequest struct {
 	ID uint `json:"id"`
 }
+
+type TagListRequest struct {
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+	Cursor     string `json:"cursor"`
+	CursorMode bool   `json:"-"`
+}
@@ -12,11 +12,22 @@ type TodoResponse struct {
 	Description string        `json:"description,omitempty"`
 	IsCompleted bool          `json:"is_completed,omitempty"`
 	DueTime     time.Time     `json:"due_time,omitempty"`
+	Recurrence  string        `json:"recurrence,omitempty"`
 	CreatedAt   time.Time     `json:"created_at,omitempty"`
 	UpdatedAt   time.Time     `json:"updated_at,omitempty"`
+	Version     uint          `json:"version,omitempty"`
+	Position    int           `json:"position,omitempty"`
+	Progress    *TodoProgress `json:"progress,omitempty"`
 	Tags        []TagResponse `json:"tags"`
 }
 
+// TodoProgress rolls up the completion of a todo's subtasks.
+type TodoProgress struct {
+	Total      int64   `json:"total"`
+	Completed  int64   `json:"completed"`
+	Percentage float64 `json:"percentage"`
+}
+
 type TodoCreateRequest struct {
 	UUID        uuid.UUID `json:"uuid"`
 	UserID      uint      `json:"user_id"`
@@ -25,6 +36,7 @@ type TodoCreateRequest struct {
 	Description string    `json:"description"  validate:"required"`
 	IsCompleted bool      `json:"is_completed"`
 	DueTime     time.Time `json:"due_time" validate:"required"`
+	Recurrence  string    `json:"recurrence" validate:"max=255"`
 }
 
 type TodoUpdateRequest struct {
@@ -36,12 +48,97 @@ type TodoUpdateRequest struct {
 	Description string    `json:"description,omitempty"`
 	IsCompleted bool      `json:"is_completed,omitempty"`
 	DueTime     time.Time `json:"due_time,omitempty"`
+	Recurrence  *string   `json:"recurrence,omitempty" validate:"omitempty,max=255"`
+	Version     *uint     `json:"version,omitempty"`
+	AllUsers    bool      `json:"-"`
 }
 
 type TodoGetDataRequest struct {
-	ID uint `json:"id"`
+	ID       uint `json:"id"`
+	AllUsers bool `json:"-"`
 }
 
 type TodoDeleteRequest struct {
-	ID uint `json:"id"`
+	ID       uint  `json:"id"`
+	Version  *uint `json:"-"`
+	AllUsers bool  `json:"-"`
+}
+
+const (
+	PatchKindMerge = "merge"
+	PatchKindJSON  = "json"
+)
+
+type TodoPatchRequest struct {
+	ID       uint
+	Version  *uint
+	AllUsers bool
+	Kind     string
+	Patch    []byte
+}
+
+// TodoPatchDocument is the representation of a todo that PATCH documents are
+// applied to. Tags are referenced by UUID and treated as a set.
+type TodoPatchDocument struct {
+	Title       string      `json:"title" validate:"required,max=255"`
+	Description string      `json:"description" validate:"required"`
+	IsCompleted bool        `json:"is_completed"`
+	DueTime     time.Time   `json:"due_time" validate:"required"`
+	Recurrence  string      `json:"recurrence" validate:"max=255"`
+	Tags        []uuid.UUID `json:"tags"`
+}
+
+type TodoOccurrenceRequest struct {
+	From     time.Time `json:"-"`
+	To       time.Time `json:"-"`
+	AllUsers bool      `json:"-"`
+}
+
+// TodoOccurrenceResponse is one upcoming occurrence of a recurring todo. The
+// first occurrence of each todo is the todo itself, the rest are projected.
+type TodoOccurrenceResponse struct {
+	TodoUUID   uuid.UUID `json:"todo_uuid"`
+	Title      string    `json:"title"`
+	DueTime    time.Time `json:"due_time"`
+	Recurrence string    `json:"recurrence"`
+	Projected  bool      `json:"projected"`
+}
+
+type TodoListRequest struct {
+	Page       int        `json:"page"`
+	Size       int        `json:"size"`
+	Cursor     string     `json:"cursor"`
+	CursorMode bool       `json:"-"`
+	AllUsers   bool       `json:"-"`
+	Filter     TodoFilter `json:"-"`
+	Sort       []SortKey  `json:"-"`
+}
+
+type TodoFilter struct {
+	IsCompleted   *bool
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	Overdue       bool
+	DueToday      bool
+	TagUUIDs      []uuid.UUID
+	TagMatchAll   bool
+	Title         string
+	Description   string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+}
+
+var TodoSortableFields = map[string]bool{
+	"title":        true,
+	"is_completed": true,
+	"due_time":     true,
+	"created_at":   true,
+	"updated_at":   true,
+}
+
+type TodoScope struct {
+	UserID   uint
+	AllUsers bool
 }
@@ -6,13 +6,28 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserResponse carries, after login or refresh, the access Token and when it
+// expires, and a RefreshToken which obtains a new pair from v1/users/_refresh
+// exactly once. PendingEmail is the address the user is changing to, until
+// it is verified.
+//
+// When TwoFactorRequired is set, the password was right but the login still
+// needs a code: ChallengeToken is sent back to v1/users/_login with it before
+// ExpiresAt.
 type UserResponse struct {
-	UUID      uuid.UUID `json:"uuid,omitempty"`
-	Name      string    `json:"name,omitempty"`
-	Email     string    `json:"email,omitempty"`
-	Token     string    `json:"token,omitempty"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
-	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	UUID              uuid.UUID  `json:"uuid,omitempty"`
+	Name              string     `json:"name,omitempty"`
+	Email             string     `json:"email,omitempty"`
+	VerifiedAt        *time.Time `json:"verified_at,omitempty"`
+	PendingEmail      string     `json:"pending_email,omitempty"`
+	TwoFactorEnabled  bool       `json:"two_factor_enabled,omitempty"`
+	TwoFactorRequired bool       `json:"two_factor_required,omitempty"`
+	ChallengeToken    string     `json:"challenge_token,omitempty"`
+	Token             string     `json:"token,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	RefreshToken      string     `json:"refresh_token,omitempty"`
+	CreatedAt         time.Time  `json:"created_at,omitempty"`
+	UpdatedAt         time.Time  `json:"updated_at,omitempty"`
 }
 
 type RegisterUserRequest struct {
@@ -21,18 +36,68 @@ type RegisterUserRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// LoginUserRequest is either the Email and Password, or the ChallengeToken
+// the password step returned together with a Code, which is a TOTP code or
+// a recovery code.
 type LoginUserRequest struct {
-	UUID     uuid.UUID `json:"uuid"`
-	Email    string    `json:"email" validate:"required,max=255"`
-	Password string    `json:"password" validate:"required,max=100"`
+	UUID           uuid.UUID `json:"uuid"`
+	Email          string    `json:"email" validate:"required_without=ChallengeToken,max=255"`
+	Password       string    `json:"password" validate:"required_without=ChallengeToken,max=100"`
+	ChallengeToken string    `json:"challenge_token" validate:"max=100"`
+	Code           string    `json:"code" validate:"required_with=ChallengeToken,max=20"`
+	UserAgent      string    `json:"-"`
+	IPAddress      string    `json:"-"`
 }
 
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=100"`
+	UserAgent    string `json:"-"`
+	IPAddress    string `json:"-"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+// ResetPasswordRequest is limited to 72 characters, the most bcrypt hashes.
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required,max=100"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required,max=1024"`
+}
+
+type ResendVerificationRequest struct {
+	GetUserId
+}
+
+// SSOStartResponse is where to send the user to sign in with the identity
+// provider. StateHash is kept by the browser that started the sign-in, and
+// the callback only accepts the state from a browser that has it.
+type SSOStartResponse struct {
+	URL       string `json:"url"`
+	StateHash string `json:"-"`
+}
+
+type SSOCallbackRequest struct {
+	Code      string `json:"code" validate:"required,max=2048"`
+	State     string `json:"state" validate:"required,max=100"`
+	StateHash string `json:"-"`
+	UserAgent string `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// UserUpdateRequest is made from session SessionID, which stays signed in
+// when the password changes while every other session is revoked.
 type UserUpdateRequest struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name,omitempty" validate:"max=100"`
-	Email       string `json:"email,omitempty" validate:"email,max=255"`
-	OldPassword string `json:"old_password,omitempty" validate:"max=100"`
-	NewPassword string `json:"new_password,omitempty" validate:"max=100"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name,omitempty" validate:"max=100"`
+	Email       string    `json:"email,omitempty" validate:"email,max=255"`
+	OldPassword string    `json:"old_password,omitempty" validate:"max=100"`
+	NewPassword string    `json:"new_password,omitempty" validate:"max=100"`
+	SessionID   uuid.UUID `json:"-"`
 }
 
 type GetUserId struct {
@@ -45,4 +110,71 @@ type CurrentUserRequest struct {
 
 type LogoutUserRequest struct {
 	GetUserId
+	SessionID uuid.UUID `json:"-"`
+}
+
+// TwoFactorEnrollResponse holds the new Secret, and the otpauth URI to show
+// as a QR code, for the user to add to their authenticator app.
+type TwoFactorEnrollResponse struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorEnrollRequest struct {
+	GetUserId
+}
+
+type TwoFactorCodeRequest struct {
+	GetUserId
+	Code string `json:"code" validate:"required,max=20"`
+}
+
+type SessionResponse struct {
+	UUID       uuid.UUID `json:"uuid"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type SessionListRequest struct {
+	GetUserId
+	SessionID uuid.UUID `json:"-"`
+}
+
+type SessionRevokeRequest struct {
+	GetUserId
+	UUID uuid.UUID `json:"uuid"`
+}
+
+const (
+	DigestOff    = "off"
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+type UserPreferenceResponse struct {
+	DigestFrequency string `json:"digest_frequency"`
+	DigestHour      int    `json:"digest_hour"`
+	DigestWeekday   int    `json:"digest_weekday"`
+	Timezone        string `json:"timezone"`
+	Locale          string `json:"locale"`
+}
+
+// UserPreferenceUpdateRequest changes only the fields that are present.
+// DigestHour is in the user's timezone and DigestWeekday counts from Sunday
+// (0) and only applies to weekly digests.
+type UserPreferenceUpdateRequest struct {
+	UserID          uint    `json:"-"`
+	DigestFrequency *string `json:"digest_frequency,omitempty" validate:"omitempty,oneof=off daily weekly"`
+	DigestHour      *int    `json:"digest_hour,omitempty" validate:"omitempty,min=0,max=23"`
+	DigestWeekday   *int    `json:"digest_weekday,omitempty" validate:"omitempty,min=0,max=6"`
+	Timezone        *string `json:"timezone,omitempty" validate:"omitempty,max=64"`
+	Locale          *string `json:"locale,omitempty" validate:"omitempty,max=10"`
 }
@@ -2,37 +2,116 @@ package internal
 
 import (
 	"go-todo-api/internal/config"
+	"go-todo-api/internal/mailer"
+	"go-todo-api/internal/oidc"
 	"go-todo-api/internal/repository/postgresql"
 	"go-todo-api/internal/rest"
 	"go-todo-api/internal/rest/middleware"
 	"go-todo-api/internal/usecase"
+	"go-todo-api/internal/util"
+	"go-todo-api/internal/workers"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gocraft/work"
+	"github.com/gomodule/redigo/redis"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 type BootstrapConfig struct {
-	DB         *gorm.DB
-	Route      *gin.Engine
-	Log        *logrus.Logger
-	JwtService *config.JwtConfig
-	Enqueurer  *work.Enqueuer
+	DB             *gorm.DB
+	Route          *gin.Engine
+	Log            *logrus.Logger
+	JwtService     *config.JwtConfig
+	TwoFactor      *config.TwoFactorConfig
+	OIDC           *config.OIDCConfig
+	Enqueurer      *work.Enqueuer
+	Cursor         *util.CursorCodec
+	Redis          *redis.Pool
+	IdempotencyTTL time.Duration
+	RequireIfMatch bool
+	WorkerPool     *work.WorkerPool
+	Mailer         mailer.Mailer
+	AppURL         string
+	OutboxPoll     time.Duration
+	DevMailbox     bool
+
+	// Verifier signs email verification links. With VerifiedOnly, users
+	// must verify their email before creating todos.
+	Verifier     *util.TokenSigner
+	VerifiedOnly bool
 }
 
-func Bootstrap(config *BootstrapConfig) {
+// Bootstrap wires the application and returns the outbox relay, which the
+// caller starts and stops alongside the worker pool.
+func Bootstrap(config *BootstrapConfig) *workers.OutboxRelay {
+	txManager := postgresql.NewTransactionManager(config.DB)
+
+	rest.NewJWKSHandler(config.Route, config.JwtService)
+
+	outboxRepo := postgresql.NewOutboxRepository(config.DB)
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepo, txManager, config.Log, config.Enqueurer)
+
+	notificationRepo := postgresql.NewNotificationRepository(config.DB)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, config.Log, outboxUsecase)
+
 	userRepo := postgresql.NewUserRepository(config.DB)
-	userUsecase := usecase.NewUserUsecase(userRepo, config.DB, config.Log, config.JwtService)
+	sessionRepo := postgresql.NewSessionRepository(config.DB)
+	twoFactorRepo := postgresql.NewTwoFactorRepository(config.DB)
+	apiKeyRepo := postgresql.NewAPIKeyRepository(config.DB)
+	ssoRepo := postgresql.NewSSORepository(config.DB)
+	var sso *oidc.Provider
+	if config.OIDC != nil {
+		sso = oidc.NewProvider(config.OIDC, nil)
+	}
+	userUsecase := usecase.NewUserUsecase(userRepo, sessionRepo, twoFactorRepo, apiKeyRepo, ssoRepo, txManager, config.Log, config.JwtService, config.TwoFactor, sso, outboxUsecase, notificationUsecase, config.AppURL, config.Verifier)
+	workers.RegisterSessionJobs(config.WorkerPool, workers.NewSessionWorker(config.Log, userUsecase))
+	workers.RegisterAccountJobs(config.WorkerPool, workers.NewAccountWorker(config.Log, userUsecase))
 	authMiddleware := middleware.NewAuth(userUsecase)
-	rest.NewUserHandler(config.Route, userUsecase, config.Log, authMiddleware)
+	idempotencyMiddleware := middleware.NewIdempotency(config.Redis, config.IdempotencyTTL, config.Log)
+	rest.NewUserHandler(config.Route, userUsecase, config.Log, authMiddleware, idempotencyMiddleware.Handle())
+
+	roleRepo := postgresql.NewRoleRepository(config.DB)
+	authzUsecase := usecase.NewAuthorizationUsecase(roleRepo, twoFactorRepo, config.Log)
+	permissionMiddleware := middleware.NewPermission(authzUsecase)
+	roleUsecase := usecase.NewRoleUsecase(roleRepo, twoFactorRepo, txManager, config.Log)
+	rest.NewRoleHandler(config.Route, roleUsecase, config.Log, permissionMiddleware)
+
+	deadLetterRepo := postgresql.NewDeadLetterRepository(config.DB)
+	deadLetterUsecase := usecase.NewDeadLetterUsecase(deadLetterRepo, txManager, config.Log, outboxUsecase)
+	rest.NewDeadLetterHandler(config.Route, deadLetterUsecase, config.Log, permissionMiddleware)
+	workers.RegisterMailJobs(config.WorkerPool, workers.NewMailWorker(config.Log, config.Mailer, deadLetterUsecase, outboxUsecase))
+
+	rest.NewEmailHandler(config.Route, notificationUsecase, config.Log, permissionMiddleware)
+	if config.DevMailbox {
+		if mailbox, ok := config.Mailer.(mailer.Mailbox); ok {
+			config.Log.Warn("Emails are captured instead of sent, see GET v1/dev/mailbox")
+			rest.NewMailboxHandler(config.Route, mailbox, config.Log, permissionMiddleware)
+		} else {
+			config.Log.Warn("DEV_MAILBOX is set, but the mail transport does not keep emails")
+		}
+	}
+
+	reminderRepo := postgresql.NewReminderRepository(config.DB)
+	reminderUsecase := usecase.NewReminderUsecase(reminderRepo, txManager, config.Log, outboxUsecase, notificationUsecase)
+	workers.RegisterReminderJobs(config.WorkerPool, workers.NewReminderWorker(config.Log, reminderUsecase))
+
+	digestRepo := postgresql.NewDigestRepository(config.DB)
+	digestUsecase := usecase.NewDigestUsecase(digestRepo, txManager, config.Log, notificationUsecase)
+	workers.RegisterDigestJobs(config.WorkerPool, workers.NewDigestWorker(config.Log, digestUsecase))
 
 	todoRepo := postgresql.NewTodoRepository(config.DB)
-	todoUsecase := usecase.NewTodoUseCase(todoRepo, config.DB, config.Log, config.JwtService, config.Enqueurer)
-	rest.NewTodoHandler(config.Route, todoUsecase, config.Log)
+	todoUsecase := usecase.NewTodoUseCase(todoRepo, txManager, config.Log, config.JwtService, notificationUsecase, authzUsecase, config.Cursor, reminderUsecase, config.VerifiedOnly)
+	rest.NewTodoHandler(config.Route, todoUsecase, config.Log, permissionMiddleware, config.RequireIfMatch)
 
 	tagRepo := postgresql.NewTagRepository(config.DB)
-	tagUsecase := usecase.NewTagUsecase(tagRepo, config.DB, config.Log, config.JwtService)
-	rest.NewTagHandler(config.Route, tagUsecase, config.Log)
+	tagUsecase := usecase.NewTagUsecase(tagRepo, txManager, config.Log, config.JwtService, config.Cursor)
+	rest.NewTagHandler(config.Route, tagUsecase, config.Log, permissionMiddleware, config.RequireIfMatch)
+
+	searchRepo := postgresql.NewSearchRepository(config.DB)
+	searchUsecase := usecase.NewSearchUsecase(searchRepo, config.Log, authzUsecase)
+	rest.NewSearchHandler(config.Route, searchUsecase, config.Log, permissionMiddleware)
 
+	return workers.NewOutboxRelay(config.Log, outboxUsecase, config.OutboxPoll)
 }
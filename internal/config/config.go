@@ -1,9 +1,14 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
+	"go-todo-api/internal/util"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gomodule/redigo/redis"
 	"gorm.io/gorm"
@@ -29,22 +34,51 @@ func InitPostgreDB() (*gorm.DB, *GormConfig, error) {
 	return dbConfig.NewGormConnection(), dbConfig, nil
 }
 
+const (
+	defaultAccessTokenTTL  = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+const defaultJwtIssuer = "go-todo-api"
+
+// InitJwtService loads the signing keys listed in JWT_KEYS_FILE and reads the
+// token lifetimes: JWT_ACCESS_TTL in minutes and JWT_REFRESH_TTL, how long an
+// unused session stays signed in, in hours. JWT_ISSUER and JWT_AUDIENCE
+// default to the service name.
 func InitJwtService() (*JwtConfig, error) {
-	jwtKey := os.Getenv("JWT_SECRET_KEY")
-	jwtExpStr := os.Getenv("JWT_EXPIRATION_TIME")
+	keysFile := os.Getenv("JWT_KEYS_FILE")
+	if keysFile == "" {
+		return nil, fmt.Errorf("JWT configuration is incomplete: JWT_KEYS_FILE is missing")
+	}
+	keys, err := LoadKeySet(keysFile)
+	if err != nil {
+		return nil, fmt.Errorf("load JWT keys: %w", err)
+	}
 
-	if jwtKey == "" || jwtExpStr == "" {
-		return nil, fmt.Errorf("JWT configuration is incomplete: JWT_SECRET_KEY or JWT_EXPIRATION_TIME is missing")
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = defaultJwtIssuer
+	}
+	audience := os.Getenv("JWT_AUDIENCE")
+	if audience == "" {
+		audience = defaultJwtIssuer
 	}
 
-	jwtExp, err := strconv.Atoi(jwtExpStr)
+	accessTTL, err := durationEnv("JWT_ACCESS_TTL", time.Minute, defaultAccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	refreshTTL, err := durationEnv("JWT_REFRESH_TTL", time.Hour, defaultRefreshTokenTTL)
 	if err != nil {
 		return nil, err
 	}
 
 	jwtService, err := NewJwtConfig(&JwtConfig{
-		JwtKey: jwtKey,
-		JwtExp: jwtExp,
+		Keys:       keys,
+		Issuer:     issuer,
+		Audience:   audience,
+		AccessTTL:  accessTTL,
+		RefreshTTL: refreshTTL,
 	})
 	if err != nil {
 		return nil, err
@@ -53,6 +87,20 @@ func InitJwtService() (*JwtConfig, error) {
 	return jwtService, nil
 }
 
+func durationEnv(key string, unit, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", key, value)
+	}
+
+	return time.Duration(n) * unit, nil
+}
+
 func InitRedis() (*redis.Pool, error) {
 	redisHost := os.Getenv("REDIS_HOST")
 	redisPort := os.Getenv("REDIS_PORT")
@@ -82,10 +130,42 @@ func InitRedis() (*redis.Pool, error) {
 	}), nil
 }
 
+const (
+	defaultMailDir    = "mailbox"
+	defaultMailSender = "no-reply@localhost"
+)
+
+// InitMailer reads MAIL_TRANSPORT, which is smtp unless set to file or
+// memory. Only the SMTP transport needs the SMTP settings; the file
+// transport writes to MAIL_DIR.
 func InitMailer() (*MailerConfig, error) {
+	transport := os.Getenv("MAIL_TRANSPORT")
+	if transport == "" {
+		transport = MailTransportSMTP
+	}
+	senderName := os.Getenv("CONFIG_SMTP_SENDER")
+
+	switch transport {
+	case MailTransportSMTP:
+	case MailTransportFile, MailTransportMemory:
+		if senderName == "" {
+			senderName = defaultMailSender
+		}
+		dir := os.Getenv("MAIL_DIR")
+		if dir == "" {
+			dir = defaultMailDir
+		}
+		return NewMailerConfig(&MailerConfig{
+			Transport:      transport,
+			SenderMailName: senderName,
+			Dir:            dir,
+		}), nil
+	default:
+		return nil, fmt.Errorf("invalid MAIL_TRANSPORT: %q", transport)
+	}
+
 	smtpHost := os.Getenv("CONFIG_SMTP_HOST")
 	smtpPortStr := os.Getenv("CONFIG_SMTP_PORT")
-	senderName := os.Getenv("CONFIG_SMTP_SENDER")
 	smtpAuthEmail := os.Getenv("CONFIG_AUTH_EMAIL")
 	smtpAuthPassword := os.Getenv("CONFIG_AUTH_PASSWORD")
 	if smtpHost == "" || smtpPortStr == "" || smtpAuthEmail == "" || smtpAuthPassword == "" || senderName == "" {
@@ -97,6 +177,7 @@ func InitMailer() (*MailerConfig, error) {
 	}
 
 	return NewMailerConfig(&MailerConfig{
+		Transport:        transport,
 		SmtpHost:         smtpHost,
 		SmtpPort:         smtpPort,
 		SenderMailName:   senderName,
@@ -104,3 +185,134 @@ func InitMailer() (*MailerConfig, error) {
 		SmtpAuthPassword: smtpAuthPassword,
 	}), nil
 }
+
+func InitCursorCodec() (*util.CursorCodec, error) {
+	secret := os.Getenv("CURSOR_SECRET_KEY")
+	if secret == "" {
+		return nil, fmt.Errorf("cursor configuration is missing: CURSOR_SECRET_KEY")
+	}
+
+	return util.NewCursorCodec(secret), nil
+}
+
+// InitEmailVerificationSigner signs the links that verify email addresses
+// with EMAIL_VERIFICATION_SECRET.
+func InitEmailVerificationSigner() (*util.TokenSigner, error) {
+	secret := os.Getenv("EMAIL_VERIFICATION_SECRET")
+	if secret == "" {
+		return nil, fmt.Errorf("email verification configuration is missing: EMAIL_VERIFICATION_SECRET")
+	}
+
+	return util.NewTokenSigner(secret), nil
+}
+
+const defaultTwoFactorIssuer = "go-todo-api"
+
+// InitTwoFactor reads TOTP_ENCRYPTION_KEY, 32 bytes in base64 such as from
+// `openssl rand -base64 32`, and TOTP_ISSUER, the name shown in
+// authenticator apps.
+func InitTwoFactor() (*TwoFactorConfig, error) {
+	encoded := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if encoded == "" {
+		return nil, fmt.Errorf("two-factor configuration is missing: TOTP_ENCRYPTION_KEY")
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: %w", err)
+	}
+	box, err := util.NewSecretBox(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: %w", err)
+	}
+
+	issuer := os.Getenv("TOTP_ISSUER")
+	if issuer == "" {
+		issuer = defaultTwoFactorIssuer
+	}
+
+	return &TwoFactorConfig{Box: box, Issuer: issuer}, nil
+}
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+func InitIdempotencyTTL() (time.Duration, error) {
+	ttlStr := os.Getenv("IDEMPOTENCY_TTL")
+	if ttlStr == "" {
+		return defaultIdempotencyTTL, nil
+	}
+
+	ttl, err := strconv.Atoi(ttlStr)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid IDEMPOTENCY_TTL: %q", ttlStr)
+	}
+
+	return time.Duration(ttl) * time.Second, nil
+}
+
+// InitRequireIfMatch turns on strict mode, where PUT and DELETE on todos and
+// tags are refused without an If-Match header.
+func InitRequireIfMatch() (bool, error) {
+	return boolEnv("REQUIRE_IF_MATCH")
+}
+
+// InitRequireVerifiedEmail turns on the policy where users cannot create
+// todos until they verify their email address.
+func InitRequireVerifiedEmail() (bool, error) {
+	return boolEnv("REQUIRE_VERIFIED_EMAIL")
+}
+
+// InitDevMailbox turns on GET v1/dev/mailbox, which lists the emails held by
+// the file or memory transport. It must stay off outside development.
+func InitDevMailbox() (bool, error) {
+	return boolEnv("DEV_MAILBOX")
+}
+
+func boolEnv(key string) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return false, nil
+	}
+
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q", key, value)
+	}
+
+	return enabled, nil
+}
+
+const defaultOutboxPollInterval = time.Second
+
+// InitOutboxPollInterval reads how often, in seconds, the outbox relay looks
+// for messages to publish.
+func InitOutboxPollInterval() (time.Duration, error) {
+	intervalStr := os.Getenv("OUTBOX_POLL_INTERVAL")
+	if intervalStr == "" {
+		return defaultOutboxPollInterval, nil
+	}
+
+	interval, err := strconv.Atoi(intervalStr)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid OUTBOX_POLL_INTERVAL: %q", intervalStr)
+	}
+
+	return time.Duration(interval) * time.Second, nil
+}
+
+const defaultAppURL = "http://localhost:8080"
+
+// InitAppURL reads APP_URL, the base of links sent in emails, such as the
+// password reset page.
+func InitAppURL() (string, error) {
+	appURL := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	if appURL == "" {
+		return defaultAppURL, nil
+	}
+
+	parsed, err := url.Parse(appURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid APP_URL: %q", appURL)
+	}
+
+	return appURL, nil
+}
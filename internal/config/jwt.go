@@ -7,51 +7,88 @@ import (
 	"go-todo-api/internal/entity"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 type JwtConfig struct {
-	JwtKey string
-	JwtExp int
+	Keys       *KeySet
+	Issuer     string
+	Audience   string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
 }
 
 type Claims struct {
-	UserID uint `json:"id"`
+	UserID    uint      `json:"id"`
+	SessionID uuid.UUID `json:"sid"`
 	jwt.RegisteredClaims
 }
 
 func NewJwtConfig(cfg *JwtConfig) (*JwtConfig, error) {
+	if cfg.Keys == nil {
+		return nil, errors.New("JWT key set is missing")
+	}
+
 	return &JwtConfig{
-		JwtKey: cfg.JwtKey,
-		JwtExp: cfg.JwtExp,
+		Keys:       cfg.Keys,
+		Issuer:     cfg.Issuer,
+		Audience:   cfg.Audience,
+		AccessTTL:  cfg.AccessTTL,
+		RefreshTTL: cfg.RefreshTTL,
 	}, nil
 }
 
-func (c *JwtConfig) CreateToken(user *entity.User) (string, error) {
+// CreateToken issues a short-lived access token for session, signed with the
+// current key and naming it in the kid header.
+func (c *JwtConfig) CreateToken(user *entity.User, session *entity.Session) (string, time.Time, error) {
+	now := time.Now()
+	key, err := c.Keys.Signing(now)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt := now.Add(c.AccessTTL)
 	claims := Claims{
-		UserID: user.ID,
+		UserID:    user.ID,
+		SessionID: session.UUID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(c.JwtExp) * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.NewString(),
+			Issuer:    c.Issuer,
+			Subject:   user.UUID.String(),
+			Audience:  jwt.ClaimStrings{c.Audience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
 
-	signedToken, err := token.SignedString([]byte(c.JwtKey))
+	signedToken, err := token.SignedString(key.Private)
 	if err != nil {
-		return "", err
+		return "", time.Time{}, err
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
 func (c *JwtConfig) ValidateToken(tokenStr string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenStr, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, err := c.Keys.Verification(kid, time.Now(), c.AccessTTL)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, errors.New("unexpected signing method")
 		}
-		return []byte(c.JwtKey), nil
-	})
+		return key.Private.Public(), nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(c.Issuer),
+		jwt.WithAudience(c.Audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -63,3 +100,8 @@ func (c *JwtConfig) ValidateToken(tokenStr string) (*Claims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// JWKS returns the public keys other services verify our tokens with.
+func (c *JwtConfig) JWKS() JWKS {
+	return c.Keys.JWKS(time.Now(), c.AccessTTL)
+}
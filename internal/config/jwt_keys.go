@@ -0,0 +1,203 @@
+package config
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// SigningKey is one entry of the key set. It signs tokens from NotBefore
+// until NotAfter; either bound may be zero for no limit.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   crypto.Signer
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// KeySet holds the keys tokens are signed and verified with. Rotation is
+// planned in the manifest: a new key is added with a NotBefore in the future
+// and is published in the JWKS right away, so verifiers know it before the
+// first token is signed with it. A retired key keeps verifying until the
+// tokens it signed have expired.
+type KeySet struct {
+	Keys []*SigningKey
+}
+
+type keyManifest struct {
+	Keys []struct {
+		ID        string    `json:"kid"`
+		Path      string    `json:"path"`
+		NotBefore time.Time `json:"not_before"`
+		NotAfter  time.Time `json:"not_after"`
+	} `json:"keys"`
+}
+
+// LoadKeySet reads a JSON manifest listing PKCS#8 PEM private keys, RSA or
+// Ed25519. Key paths are relative to the manifest.
+func LoadKeySet(path string) (*KeySet, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest keyManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	set := &KeySet{}
+	seen := make(map[string]bool, len(manifest.Keys))
+	for _, entry := range manifest.Keys {
+		if entry.ID == "" || seen[entry.ID] {
+			return nil, fmt.Errorf("key ids must be unique and not empty: %q", entry.ID)
+		}
+		seen[entry.ID] = true
+
+		keyPath := entry.Path
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+		private, method, err := readPrivateKey(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.ID, err)
+		}
+
+		set.Keys = append(set.Keys, &SigningKey{
+			ID:        entry.ID,
+			Method:    method,
+			Private:   private,
+			NotBefore: entry.NotBefore,
+			NotAfter:  entry.NotAfter,
+		})
+	}
+
+	if _, err := set.Signing(time.Now()); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+func readPrivateKey(path string) (crypto.Signer, jwt.SigningMethod, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, nil, errors.New("no PEM block found")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 2048 {
+			return nil, nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		return key, jwt.SigningMethodRS256, nil
+	case ed25519.PrivateKey:
+		return key, jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+func (k *SigningKey) signsAt(now time.Time) bool {
+	return !now.Before(k.NotBefore) && (k.NotAfter.IsZero() || now.Before(k.NotAfter))
+}
+
+// verifiesAt reports whether tokens signed by k can still be valid at now,
+// given they live at most ttl.
+func (k *SigningKey) verifiesAt(now time.Time, ttl time.Duration) bool {
+	return k.NotAfter.IsZero() || now.Before(k.NotAfter.Add(ttl))
+}
+
+// Signing returns the newest key whose window includes now.
+func (s *KeySet) Signing(now time.Time) (*SigningKey, error) {
+	var current *SigningKey
+	for _, key := range s.Keys {
+		if key.signsAt(now) && (current == nil || key.NotBefore.After(current.NotBefore)) {
+			current = key
+		}
+	}
+	if current == nil {
+		return nil, errors.New("no JWT signing key is valid now")
+	}
+	return current, nil
+}
+
+// Verification returns key kid if tokens it signed can still be valid.
+func (s *KeySet) Verification(kid string, now time.Time, ttl time.Duration) (*SigningKey, error) {
+	for _, key := range s.Keys {
+		if key.ID == kid {
+			if !key.verifiesAt(now, ttl) || now.Before(key.NotBefore) {
+				return nil, fmt.Errorf("key %q is not in use", kid)
+			}
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	ID        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys verifiers need at now: keys still verifying
+// and keys scheduled to start signing, oldest first.
+func (s *KeySet) JWKS(now time.Time, ttl time.Duration) JWKS {
+	keys := make([]*SigningKey, 0, len(s.Keys))
+	for _, key := range s.Keys {
+		if key.verifiesAt(now, ttl) {
+			keys = append(keys, key)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].NotBefore.Before(keys[j].NotBefore)
+	})
+
+	set := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		jwk := JWK{ID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch public := key.Private.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
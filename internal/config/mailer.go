@@ -1,48 +1,32 @@
 package config
 
-import (
-	"log"
-
-	"gopkg.in/gomail.v2"
+const (
+	MailTransportSMTP   = "smtp"
+	MailTransportFile   = "file"
+	MailTransportMemory = "memory"
 )
 
+// MailerConfig selects how emails leave the application. The file and memory
+// transports capture messages instead of sending them and are meant for
+// local development and tests.
 type MailerConfig struct {
+	Transport        string
 	SmtpHost         string
 	SmtpPort         int
 	SenderMailName   string
 	SmtpAuthEmail    string
 	SmtpAuthPassword string
+	Dir              string
 }
 
 func NewMailerConfig(cfg *MailerConfig) *MailerConfig {
 	return &MailerConfig{
+		Transport:        cfg.Transport,
 		SmtpHost:         cfg.SmtpHost,
 		SmtpPort:         cfg.SmtpPort,
 		SenderMailName:   cfg.SenderMailName,
 		SmtpAuthEmail:    cfg.SmtpAuthEmail,
 		SmtpAuthPassword: cfg.SmtpAuthPassword,
+		Dir:              cfg.Dir,
 	}
 }
-
-func (c *MailerConfig) SendMail(to string, subject, message string) error {
-	m := gomail.NewMessage()
-
-	m.SetHeader("From", c.SenderMailName)
-	m.SetHeader("To", to)
-	m.SetHeader("Subject", subject)
-	m.SetBody("text/html", message)
-
-	dialer := gomail.NewDialer(
-		c.SmtpHost,
-		c.SmtpPort,
-		c.SmtpAuthEmail,
-		c.SmtpAuthPassword,
-	)
-
-	errDial := dialer.DialAndSend(m)
-	if errDial != nil {
-		log.Fatal(errDial.Error())
-	}
-
-	return nil
-}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"fmt"
+	"go-todo-api/internal/entity"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	defaultOIDCScopes      = "openid email profile"
+	defaultOIDCGroupsClaim = "groups"
+)
+
+// OIDCConfig describes the identity provider users can sign in with.
+// GroupRoles maps groups from the GroupsClaim of the ID token onto the
+// user_role enum; when it is empty, roles are left as they are.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	GroupRoles   map[string]string
+}
+
+// InitOIDC reads the identity provider from OIDC_ISSUER, OIDC_CLIENT_ID,
+// OIDC_CLIENT_SECRET and OIDC_REDIRECT_URL, the callback registered with the
+// provider. OIDC_SCOPES is space separated and OIDC_GROUP_ROLES a comma
+// separated list of group=role pairs, such as "platform-admins=admin".
+// Single sign-on is off, and InitOIDC returns nil, without OIDC_ISSUER.
+func InitOIDC() (*OIDCConfig, error) {
+	issuer := strings.TrimRight(os.Getenv("OIDC_ISSUER"), "/")
+	if issuer == "" {
+		return nil, nil
+	}
+
+	cfg := &OIDCConfig{
+		Issuer:       issuer,
+		ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		GroupsClaim:  os.Getenv("OIDC_GROUPS_CLAIM"),
+		GroupRoles:   make(map[string]string),
+	}
+	if cfg.ClientID == "" || cfg.RedirectURL == "" {
+		return nil, fmt.Errorf("OIDC configuration is incomplete: OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required")
+	}
+	for key, value := range map[string]string{"OIDC_ISSUER": cfg.Issuer, "OIDC_REDIRECT_URL": cfg.RedirectURL} {
+		parsed, err := url.Parse(value)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid %s: %q", key, value)
+		}
+	}
+
+	scopes := os.Getenv("OIDC_SCOPES")
+	if scopes == "" {
+		scopes = defaultOIDCScopes
+	}
+	cfg.Scopes = strings.Fields(scopes)
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = defaultOIDCGroupsClaim
+	}
+
+	if mapping := os.Getenv("OIDC_GROUP_ROLES"); mapping != "" {
+		for _, pair := range strings.Split(mapping, ",") {
+			group, role, found := strings.Cut(strings.TrimSpace(pair), "=")
+			group, role = strings.TrimSpace(group), strings.TrimSpace(role)
+			if !found || group == "" || (role != entity.RoleAdmin && role != entity.RoleUser) {
+				return nil, fmt.Errorf("invalid OIDC_GROUP_ROLES entry: %q", pair)
+			}
+			cfg.GroupRoles[group] = role
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,10 @@
+package config
+
+import "go-todo-api/internal/util"
+
+// TwoFactorConfig holds the key TOTP secrets are encrypted with and the
+// Issuer authenticator apps show next to the account.
+type TwoFactorConfig struct {
+	Box    *util.SecretBox
+	Issuer string
+}
@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey lets scripts act as its user without signing in. Only the hash of
+// its secret is stored; Prefix is public and finds the key. Requests made
+// with it may only use the permissions listed in Scopes.
+type APIKey struct {
+	ID         uint64        `gorm:"column:id;primaryKey"`
+	UUID       uuid.UUID     `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	UserID     uint          `gorm:"column:user_id"`
+	Name       string        `gorm:"column:name"`
+	Prefix     string        `gorm:"column:prefix"`
+	SecretHash string        `gorm:"column:secret_hash"`
+	ExpiresAt  *time.Time    `gorm:"column:expires_at"`
+	LastUsedAt *time.Time    `gorm:"column:last_used_at"`
+	CreatedAt  time.Time     `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  time.Time     `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	Scopes     []APIKeyScope `gorm:"foreignKey:APIKeyID;references:ID"`
+	User       User          `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (a *APIKey) TableName() string {
+	return "api_keys"
+}
+
+type APIKeyScope struct {
+	ID       uint64 `gorm:"column:id;primaryKey"`
+	APIKeyID uint64 `gorm:"column:api_key_id"`
+	Scope    string `gorm:"column:scope"`
+}
+
+func (a *APIKeyScope) TableName() string {
+	return "api_key_scopes"
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeadLetter struct {
+	ID        uint64    `gorm:"column:id;primaryKey"`
+	UUID      uuid.UUID `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	JobName   string    `gorm:"column:job_name"`
+	JobID     string    `gorm:"column:job_id"`
+	Payload   []byte    `gorm:"column:payload;type:jsonb"`
+	Attempts  int       `gorm:"column:attempts"`
+	LastError string    `gorm:"column:last_error"`
+	FailedAt  time.Time `gorm:"column:failed_at"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (d *DeadLetter) TableName() string {
+	return "dead_letters"
+}
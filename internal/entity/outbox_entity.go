@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OutboxMessage struct {
+	ID           uint64     `gorm:"column:id;primaryKey"`
+	UUID         uuid.UUID  `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	JobName      string     `gorm:"column:job_name"`
+	Payload      []byte     `gorm:"column:payload;type:jsonb"`
+	Attempts     int        `gorm:"column:attempts"`
+	LastError    string     `gorm:"column:last_error"`
+	AvailableAt  time.Time  `gorm:"column:available_at"`
+	DispatchedAt *time.Time `gorm:"column:dispatched_at"`
+	ProcessedAt  *time.Time `gorm:"column:processed_at"`
+	CreatedAt    time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (o *OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+type PasswordReset struct {
+	ID        uint64     `gorm:"column:id;primaryKey"`
+	UserID    uint       `gorm:"column:user_id"`
+	TokenHash string     `gorm:"column:token_hash"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (p *PasswordReset) TableName() string {
+	return "password_resets"
+}
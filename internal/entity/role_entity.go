@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Role struct {
+	ID          uint             `gorm:"column:id;primaryKey"`
+	UUID        uuid.UUID        `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	Name        string           `gorm:"column:name"`
+	CreatedAt   time.Time        `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt   time.Time        `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	DeletedAt   gorm.DeletedAt   `gorm:"column:deleted_at;autoDeleteTime:milli"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;references:ID"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+type RolePermission struct {
+	ID         uint      `gorm:"column:id;primaryKey"`
+	RoleID     uint      `gorm:"column:role_id"`
+	Permission string    `gorm:"column:permission"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *RolePermission) TableName() string {
+	return "role_permissions"
+}
@@ -0,0 +1,13 @@
+package entity
+
+import "github.com/google/uuid"
+
+// SearchResult is a row of the full-text search query over todos and tags,
+// it is not backed by a table of its own.
+type SearchResult struct {
+	Type    string    `gorm:"column:type"`
+	UUID    uuid.UUID `gorm:"column:uuid"`
+	Title   string    `gorm:"column:title"`
+	Snippet string    `gorm:"column:snippet"`
+	Rank    float32   `gorm:"column:rank"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. It stays valid until ExpiresAt, which
+// every refresh moves forward, or until it is revoked.
+type Session struct {
+	ID         uint64     `gorm:"column:id;primaryKey"`
+	UUID       uuid.UUID  `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	UserID     uint       `gorm:"column:user_id"`
+	UserAgent  string     `gorm:"column:user_agent"`
+	IPAddress  string     `gorm:"column:ip_address"`
+	ExpiresAt  time.Time  `gorm:"column:expires_at"`
+	LastUsedAt time.Time  `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User       User       `gorm:"foreignKey:UserID;references:ID"`
+}
+
+func (s *Session) TableName() string {
+	return "sessions"
+}
+
+type RefreshToken struct {
+	ID        uint64     `gorm:"column:id;primaryKey"`
+	SessionID uint64     `gorm:"column:session_id"`
+	TokenHash string     `gorm:"column:token_hash"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+	Session   Session    `gorm:"foreignKey:SessionID;references:ID"`
+}
+
+func (r *RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
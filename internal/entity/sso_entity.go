@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// UserIdentity links a user to the account Subject at the OIDC provider
+// Issuer, so later sign-ins find the user even if either email changes.
+type UserIdentity struct {
+	ID          uint64     `gorm:"column:id;primaryKey"`
+	UserID      uint       `gorm:"column:user_id"`
+	Issuer      string     `gorm:"column:issuer"`
+	Subject     string     `gorm:"column:subject"`
+	Email       string     `gorm:"column:email"`
+	LastLoginAt *time.Time `gorm:"column:last_login_at"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (u *UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// SSOState is a sign-in started at the OIDC provider. The state sent there
+// is stored hashed and comes back once, with the nonce expected in the ID
+// token and the PKCE verifier the code is redeemed with.
+type SSOState struct {
+	ID           uint64    `gorm:"column:id;primaryKey"`
+	StateHash    string    `gorm:"column:state_hash"`
+	Nonce        string    `gorm:"column:nonce"`
+	CodeVerifier string    `gorm:"column:code_verifier"`
+	ExpiresAt    time.Time `gorm:"column:expires_at"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (s *SSOState) TableName() string {
+	return "sso_states"
+}
@@ -11,6 +11,7 @@ type Tag struct {
 	ID        uint           `gorm:"column:id;primaryKey"`
 	UUID      uuid.UUID      `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
 	Name      string         `gorm:"column:name"`
+	Version   uint           `gorm:"column:version;default:1"`
 	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime:milli"`
 	UpdatedAt time.Time      `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
 	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;autoDeleteTime:milli"`
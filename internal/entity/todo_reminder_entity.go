@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TodoReminder is delivered by the outbox message with UUID JobID, which is
+// published at ScheduledFor, a Unix time.
+type TodoReminder struct {
+	ID            uint       `gorm:"column:id;primaryKey"`
+	UUID          uuid.UUID  `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	TodoID        uint       `gorm:"column:todo_id"`
+	MinutesBefore int        `gorm:"column:minutes_before"`
+	RemindAt      time.Time  `gorm:"column:remind_at"`
+	SentAt        *time.Time `gorm:"column:sent_at"`
+	JobID         string     `gorm:"column:job_id"`
+	ScheduledFor  int64      `gorm:"column:scheduled_for"`
+	CreatedAt     time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt     time.Time  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+}
+
+func (t *TodoReminder) TableName() string {
+	return "todo_reminders"
+}
@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+type RecoveryCode struct {
+	ID        uint64     `gorm:"column:id;primaryKey"`
+	UserID    uint       `gorm:"column:user_id"`
+	CodeHash  string     `gorm:"column:code_hash"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (r *RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// LoginChallenge is issued when a password was correct for a user with
+// two-factor authentication, and is exchanged for a session together with a
+// valid code.
+type LoginChallenge struct {
+	ID        uint64     `gorm:"column:id;primaryKey"`
+	UserID    uint       `gorm:"column:user_id"`
+	TokenHash string     `gorm:"column:token_hash"`
+	Attempts  int        `gorm:"column:attempts"`
+	ExpiresAt time.Time  `gorm:"column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"`
+	CreatedAt time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (l *LoginChallenge) TableName() string {
+	return "login_challenges"
+}
+
+// TwoFactorPolicy requires two-factor authentication of every user with the
+// built-in Role.
+type TwoFactorPolicy struct {
+	Role      string    `gorm:"column:role;primaryKey"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime:milli"`
+}
+
+func (t *TwoFactorPolicy) TableName() string {
+	return "two_factor_policies"
+}
@@ -7,18 +7,37 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
 type User struct {
-	ID        uint           `gorm:"column:id;primaryKey"`
-	UUID      uuid.UUID      `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
-	Name      string         `gorm:"column:name"`
-	Email     string         `gorm:"column:email"`
-	Password  string         `gorm:"column:password"`
-	Token     string         `gorm:"column:token"`
-	Role      string         `gorm:"column:role;default:'user'"`
-	CreatedAt time.Time      `gorm:"column:created_at;autoCreateTime:milli"`
-	UpdatedAt time.Time      `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
-	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;autoDeleteTime:milli"`
-	Todo      []Todo         `gorm:"foreignKey:user_id;references:id"`
+	ID       uint      `gorm:"column:id;primaryKey"`
+	UUID     uuid.UUID `gorm:"column:uuid;type:uuid;default:gen_random_uuid()"`
+	Name     string    `gorm:"column:name"`
+	Email    string    `gorm:"column:email"`
+	Password string    `gorm:"column:password"`
+	Role     string    `gorm:"column:role;default:'user'"`
+	RoleID   *uint     `gorm:"column:role_id"`
+	// VerifiedAt is when Email was confirmed. A new address waits in
+	// PendingEmail until it is confirmed too.
+	VerifiedAt         *time.Time `gorm:"column:verified_at"`
+	PendingEmail       *string    `gorm:"column:pending_email"`
+	VerificationSentAt *time.Time `gorm:"column:verification_sent_at"`
+	// TOTPSecret is encrypted and set from the start of enrollment, but only
+	// required at login once TOTPEnabledAt is set. TOTPLastStep is the last
+	// time step a code was accepted for, so a code cannot be used twice.
+	TOTPSecret    *string        `gorm:"column:totp_secret"`
+	TOTPEnabledAt *time.Time     `gorm:"column:totp_enabled_at"`
+	TOTPLastStep  int64          `gorm:"column:totp_last_step"`
+	CreatedAt     time.Time      `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt     time.Time      `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	DeletedAt     gorm.DeletedAt `gorm:"column:deleted_at;autoDeleteTime:milli"`
+	Todo          []Todo         `gorm:"foreignKey:user_id;references:id"`
+	// Scopes is set when the request was authenticated with an API key and
+	// limits it to those permissions; it is nil for signed-in users.
+	Scopes []string `gorm:"-"`
 }
 
 func (u *User) TableName() string {
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+type UserPreference struct {
+	UserID          uint       `gorm:"column:user_id;primaryKey;autoIncrement:false"`
+	DigestFrequency string     `gorm:"column:digest_frequency;default:'off'"`
+	DigestHour      int        `gorm:"column:digest_hour"`
+	DigestWeekday   int        `gorm:"column:digest_weekday"`
+	Timezone        string     `gorm:"column:timezone;default:'UTC'"`
+	Locale          string     `gorm:"column:locale;default:'en'"`
+	LastDigestAt    *time.Time `gorm:"column:last_digest_at"`
+	CreatedAt       time.Time  `gorm:"column:created_at;autoCreateTime:milli"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at;autoCreateTime:milli;autoUpdateTime:milli"`
+	User            User       `gorm:"foreignKey:user_id;references:id"`
+}
+
+func (p *UserPreference) TableName() string {
+	return "user_preferences"
+}
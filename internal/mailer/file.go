@@ -0,0 +1,151 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// fileListLimit bounds how many messages Messages reads from the directory.
+const fileListLimit = 200
+
+// File writes every message as an .eml file in Dir, which any mail client
+// can open.
+type File struct {
+	Dir  string
+	From string
+}
+
+func NewFile(dir, from string) (*File, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create mail directory: %w", err)
+	}
+	return &File{Dir: dir, From: from}, nil
+}
+
+func (f *File) Send(message *Message) error {
+	message.SentAt = time.Now()
+
+	// The timestamp prefix keeps names in send order; the file is renamed
+	// into place so readers never see a partial message.
+	name := fmt.Sprintf("%d-%s.eml", message.SentAt.UnixNano(), uuid.NewString())
+	tmp, err := os.CreateTemp(f.Dir, ".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := compose(f.From, message).WriteTo(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(f.Dir, name))
+}
+
+// Messages reads back the most recent messages, newest first.
+func (f *File) Messages() ([]Message, error) {
+	names, err := filepath.Glob(filepath.Join(f.Dir, "*.eml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	if len(names) > fileListLimit {
+		names = names[:fileListLimit]
+	}
+
+	messages := make([]Message, 0, len(names))
+	for _, name := range names {
+		message, err := readMessage(name)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", filepath.Base(name), err)
+		}
+		messages = append(messages, *message)
+	}
+	return messages, nil
+}
+
+func readMessage(name string) (*Message, error) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(parsed.Header.Get("Subject"))
+	if err != nil {
+		return nil, err
+	}
+	sentAt, _ := parsed.Header.Date()
+
+	message := &Message{
+		To:      parsed.Header.Get("To"),
+		Subject: subject,
+		SentAt:  sentAt,
+	}
+	err = readBody(message, parsed.Header.Get("Content-Type"), parsed.Header.Get("Content-Transfer-Encoding"), parsed.Body)
+	return message, err
+}
+
+// readBody fills the HTML and text parts of message from a single part or a
+// multipart body.
+func readBody(message *Message, contentType, encoding string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return err
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			err = readBody(message, part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	switch strings.ToLower(encoding) {
+	case "quoted-printable":
+		body = quotedprintable.NewReader(body)
+	case "base64":
+		body = base64.NewDecoder(base64.StdEncoding, body)
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+
+	switch mediaType {
+	case "text/html":
+		message.HTML = string(content)
+	case "text/plain":
+		message.Text = string(content)
+	}
+	return nil
+}
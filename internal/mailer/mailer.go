@@ -0,0 +1,61 @@
+// Package mailer delivers outgoing emails through a transport chosen by
+// configuration: SMTP, or a file or in-memory sink for development.
+package mailer
+
+import (
+	"fmt"
+	"go-todo-api/internal/config"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string
+	SentAt  time.Time
+}
+
+type Mailer interface {
+	Send(message *Message) error
+}
+
+// Mailbox is implemented by transports that capture messages instead of
+// delivering them.
+type Mailbox interface {
+	Messages() ([]Message, error)
+}
+
+func New(cfg *config.MailerConfig) (Mailer, error) {
+	switch cfg.Transport {
+	case config.MailTransportSMTP:
+		return NewSMTP(cfg.SmtpHost, cfg.SmtpPort, cfg.SmtpAuthEmail, cfg.SmtpAuthPassword, cfg.SenderMailName), nil
+	case config.MailTransportFile:
+		return NewFile(cfg.Dir, cfg.SenderMailName)
+	case config.MailTransportMemory:
+		return NewMemory(cfg.SenderMailName), nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q", cfg.Transport)
+	}
+}
+
+// compose builds the MIME message. When a plain-text part is set it comes
+// first, with HTML as the alternative mail clients prefer when they can
+// render it.
+func compose(from string, message *Message) *gomail.Message {
+	m := gomail.NewMessage()
+
+	m.SetHeader("From", from)
+	m.SetHeader("To", message.To)
+	m.SetHeader("Subject", message.Subject)
+	m.SetDateHeader("Date", message.SentAt)
+	if message.Text != "" {
+		m.SetBody("text/plain", message.Text)
+		m.AddAlternative("text/html", message.HTML)
+	} else {
+		m.SetBody("text/html", message.HTML)
+	}
+	return m
+}
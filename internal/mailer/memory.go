@@ -0,0 +1,53 @@
+package mailer
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryCapacity bounds the in-memory mailbox; the oldest messages are
+// dropped first.
+const memoryCapacity = 200
+
+// Memory keeps sent messages in the process, newest last. It only sees
+// emails sent by workers running in the same process.
+type Memory struct {
+	From string
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemory(from string) *Memory {
+	return &Memory{From: from}
+}
+
+func (m *Memory) Send(message *Message) error {
+	message.SentAt = time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, *message)
+	if len(m.messages) > memoryCapacity {
+		m.messages = append([]Message(nil), m.messages[len(m.messages)-memoryCapacity:]...)
+	}
+	return nil
+}
+
+// Messages returns the captured messages, newest first.
+func (m *Memory) Messages() ([]Message, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]Message, 0, len(m.messages))
+	for i := len(m.messages) - 1; i >= 0; i-- {
+		messages = append(messages, m.messages[i])
+	}
+	return messages, nil
+}
+
+func (m *Memory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = nil
+}
@@ -0,0 +1,28 @@
+package mailer
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+type SMTP struct {
+	Dialer *gomail.Dialer
+	From   string
+}
+
+func NewSMTP(host string, port int, username, password, from string) *SMTP {
+	return &SMTP{
+		Dialer: gomail.NewDialer(host, port, username, password),
+		From:   from,
+	}
+}
+
+func (s *SMTP) Send(message *Message) error {
+	message.SentAt = time.Now()
+	if err := s.Dialer.DialAndSend(compose(s.From, message)); err != nil {
+		return fmt.Errorf("send mail to %s: %w", message.To, err)
+	}
+	return nil
+}
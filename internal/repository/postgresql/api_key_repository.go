@@ -0,0 +1,85 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	DB *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		DB: db,
+	}
+}
+
+// Create stores key together with its scopes.
+func (r *APIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	return dbFromContext(ctx, r.DB).Omit("User").Create(key).Error
+}
+
+func (r *APIKeyRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.APIKey{}).
+		Where("user_id = ?", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *APIKeyRepository) FindByUserID(ctx context.Context, userID uint) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	err := dbFromContext(ctx, r.DB).
+		Preload("Scopes").
+		Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// FindByPrefix returns the key with its scopes and user, provided the user
+// is not deleted.
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	err := dbFromContext(ctx, r.DB).
+		InnerJoins("User").
+		Preload("Scopes").
+		Where("api_keys.prefix = ?", prefix).
+		Take(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Touch records that the key was used at at, unless it already was after
+// since, so busy keys do not write on every request.
+func (r *APIKeyRepository) Touch(ctx context.Context, id uint64, at, since time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, since).
+		UpdateColumn("last_used_at", at).Error
+}
+
+func (r *APIKeyRepository) DeleteByUserID(ctx context.Context, userID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Where("user_id = ?", userID).
+		Delete(&entity.APIKey{}).Error
+}
+
+// DeleteByUUID deletes a key of userID and reports whether there was one.
+func (r *APIKeyRepository) DeleteByUUID(ctx context.Context, userID uint, id uuid.UUID) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Where("user_id = ? AND uuid = ?", userID, id).
+		Delete(&entity.APIKey{})
+	return result.RowsAffected > 0, result.Error
+}
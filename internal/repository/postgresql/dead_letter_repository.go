@@ -0,0 +1,47 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DeadLetterRepository struct {
+	*BaseRepository[entity.DeadLetter]
+	DB *gorm.DB
+}
+
+func NewDeadLetterRepository(db *gorm.DB) *DeadLetterRepository {
+	return &DeadLetterRepository{
+		BaseRepository: NewBaseRepository[entity.DeadLetter](db),
+		DB:             db,
+	}
+}
+
+func (r *DeadLetterRepository) FindByUUID(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error) {
+	var deadLetter entity.DeadLetter
+	err := dbFromContext(ctx, r.DB).
+		Where("uuid = ?", id).
+		Take(&deadLetter).Error
+	if err != nil {
+		return nil, err
+	}
+	return &deadLetter, nil
+}
+
+// FindAll lists dead letters, most recent failure first, optionally only
+// those of jobName.
+func (r *DeadLetterRepository) FindAll(ctx context.Context, jobName string, page, size int) ([]entity.DeadLetter, error) {
+	var deadLetters []entity.DeadLetter
+	query := r.Paginate(dbFromContext(ctx, r.DB), page, size)
+	if jobName != "" {
+		query = query.Where("job_name = ?", jobName)
+	}
+	err := query.Order("failed_at DESC, id DESC").Find(&deadLetters).Error
+	if err != nil {
+		return nil, err
+	}
+	return deadLetters, nil
+}
@@ -0,0 +1,90 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DigestRepository struct {
+	DB *gorm.DB
+}
+
+func NewDigestRepository(db *gorm.DB) *DigestRepository {
+	return &DigestRepository{DB: db}
+}
+
+// FindSubscribers pages through the preferences of active users with digests
+// turned on, ordered by user id.
+func (r *DigestRepository) FindSubscribers(ctx context.Context, afterUserID uint, limit int) ([]entity.UserPreference, error) {
+	var preferences []entity.UserPreference
+	err := dbFromContext(ctx, r.DB).
+		Joins("JOIN users ON users.id = user_preferences.user_id AND users.deleted_at IS NULL").
+		Where("user_preferences.digest_frequency <> 'off'").
+		Where("user_preferences.user_id > ?", afterUserID).
+		Order("user_preferences.user_id").
+		Limit(limit).
+		Find(&preferences).Error
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// ClaimDigest records a digest for the slot starting at slot. It reports
+// false when one was already sent for that slot.
+func (r *DigestRepository) ClaimDigest(ctx context.Context, userID uint, slot, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.UserPreference{}).
+		Where("user_id = ? AND (last_digest_at IS NULL OR last_digest_at < ?)", userID, slot).
+		UpdateColumn("last_digest_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *DigestRepository) openTodos(ctx context.Context, userID uint) *gorm.DB {
+	return dbFromContext(ctx, r.DB).
+		Where("user_id = ? AND parent_id IS NULL AND is_completed = FALSE", userID)
+}
+
+func (r *DigestRepository) FindOverdue(ctx context.Context, userID uint, now time.Time, limit int) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := r.openTodos(ctx, userID).
+		Where("due_time < ?", now).
+		Order("due_time").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+// FindDueBetween returns open todos due in [from, to).
+func (r *DigestRepository) FindDueBetween(ctx context.Context, userID uint, from, to time.Time, limit int) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := r.openTodos(ctx, userID).
+		Where("due_time >= ? AND due_time < ?", from, to).
+		Order("due_time").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (r *DigestRepository) FindCompletedSince(ctx context.Context, userID uint, since time.Time, limit int) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := dbFromContext(ctx, r.DB).
+		Where("user_id = ? AND parent_id IS NULL AND is_completed = TRUE", userID).
+		Where("updated_at >= ?", since).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
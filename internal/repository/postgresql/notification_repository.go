@@ -0,0 +1,32 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	DB *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{DB: db}
+}
+
+func (r *NotificationRepository) FindUserByID(ctx context.Context, id uint) (*entity.User, error) {
+	var user entity.User
+	if err := dbFromContext(ctx, r.DB).Where("id = ?", id).Take(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *NotificationRepository) FindPreference(ctx context.Context, userID uint) (*entity.UserPreference, error) {
+	var preference entity.UserPreference
+	if err := dbFromContext(ctx, r.DB).Where("user_id = ?", userID).Take(&preference).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
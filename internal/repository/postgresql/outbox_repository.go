@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OutboxRepository struct {
+	*BaseRepository[entity.OutboxMessage]
+	DB *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{
+		BaseRepository: NewBaseRepository[entity.OutboxMessage](db),
+		DB:             db,
+	}
+}
+
+// LockPending locks up to limit messages that are due for dispatch. Rows
+// locked by another relay are skipped, so several relays can run at once.
+// It must be called inside a transaction.
+func (r *OutboxRepository) LockPending(ctx context.Context, now time.Time, limit int) ([]entity.OutboxMessage, error) {
+	var messages []entity.OutboxMessage
+	err := dbFromContext(ctx, r.DB).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("dispatched_at IS NULL AND available_at <= ?", now).
+		Order("available_at, id").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkDispatched(ctx context.Context, id uint64, at time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"dispatched_at": at,
+			"attempts":      gorm.Expr("attempts + 1"),
+			"last_error":    "",
+		}).Error
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint64, reason string, retryAt time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"available_at": retryAt,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   reason,
+		}).Error
+}
+
+func (r *OutboxRepository) DeletePending(ctx context.Context, id uuid.UUID) error {
+	return dbFromContext(ctx, r.DB).
+		Where("uuid = ? AND dispatched_at IS NULL", id).
+		Delete(&entity.OutboxMessage{}).Error
+}
+
+func (r *OutboxRepository) IsProcessed(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.OutboxMessage{}).
+		Where("uuid = ? AND processed_at IS NOT NULL", id).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// MarkProcessed records that the job of message id ran, and reports false if
+// that was already recorded.
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.OutboxMessage{}).
+		Where("uuid = ? AND processed_at IS NULL", id).
+		Update("processed_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteDispatchedBefore prunes dispatched messages older than before.
+func (r *OutboxRepository) DeleteDispatchedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.DB).
+		Where("dispatched_at IS NOT NULL AND dispatched_at < ?", before).
+		Delete(&entity.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,106 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReminderRepository struct {
+	*BaseRepository[entity.TodoReminder]
+	DB *gorm.DB
+}
+
+func NewReminderRepository(db *gorm.DB) *ReminderRepository {
+	return &ReminderRepository{
+		BaseRepository: NewBaseRepository[entity.TodoReminder](db),
+		DB:             db,
+	}
+}
+
+func (r *ReminderRepository) FindByTodoID(ctx context.Context, todoID uint) ([]entity.TodoReminder, error) {
+	var reminders []entity.TodoReminder
+	err := dbFromContext(ctx, r.DB).
+		Where("todo_id = ?", todoID).
+		Order("minutes_before DESC").
+		Find(&reminders).Error
+	if err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+func (r *ReminderRepository) DeleteByTodoID(ctx context.Context, todoID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Where("todo_id = ?", todoID).
+		Delete(&entity.TodoReminder{}).Error
+}
+
+// MarkSent claims a reminder for delivery. It reports false when another
+// worker already sent it.
+func (r *ReminderRepository) MarkSent(ctx context.Context, id uint, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.TodoReminder{}).
+		Where("id = ? AND sent_at IS NULL", id).
+		UpdateColumn("sent_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindDue returns unsent reminders of open todos that fell due in [from, to].
+func (r *ReminderRepository) FindDue(ctx context.Context, from, to time.Time, limit int) ([]entity.TodoReminder, error) {
+	var reminders []entity.TodoReminder
+	err := dbFromContext(ctx, r.DB).
+		Joins("JOIN todos ON todos.id = todo_reminders.todo_id AND todos.deleted_at IS NULL").
+		Where("todo_reminders.sent_at IS NULL").
+		Where("todo_reminders.remind_at BETWEEN ? AND ?", from, to).
+		Where("todos.is_completed = FALSE").
+		Order("todo_reminders.remind_at").
+		Limit(limit).
+		Find(&reminders).Error
+	if err != nil {
+		return nil, err
+	}
+	return reminders, nil
+}
+
+// FindOverdueTodos returns open todos with reminders whose deadline passed
+// in [from, to] and that have not had their overdue notice yet.
+func (r *ReminderRepository) FindOverdueTodos(ctx context.Context, from, to time.Time, limit int) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := dbFromContext(ctx, r.DB).
+		Where("todos.is_completed = FALSE AND todos.overdue_notified_at IS NULL").
+		Where("todos.due_time BETWEEN ? AND ?", from, to).
+		Where("EXISTS (SELECT 1 FROM todo_reminders WHERE todo_reminders.todo_id = todos.id)").
+		Order("todos.due_time").
+		Limit(limit).
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (r *ReminderRepository) MarkOverdueNotified(ctx context.Context, todoID uint, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("id = ? AND overdue_notified_at IS NULL", todoID).
+		UpdateColumn("overdue_notified_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *ReminderRepository) ResetOverdueNotice(ctx context.Context, todoID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("id = ?", todoID).
+		UpdateColumn("overdue_notified_at", nil).Error
+}
+
+func (r *ReminderRepository) FindTodoByID(ctx context.Context, id uint) (*entity.Todo, error) {
+	var todo entity.Todo
+	if err := dbFromContext(ctx, r.DB).Where("id = ?", id).Take(&todo).Error; err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
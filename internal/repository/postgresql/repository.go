@@ -2,8 +2,16 @@ package postgresql
 
 import (
 	"context"
+	"fmt"
+	"go-todo-api/domain"
+	"go-todo-api/internal/util"
+	"reflect"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
 )
 
 type BaseRepository[T any] struct {
@@ -15,20 +23,20 @@ func NewBaseRepository[T any](db *gorm.DB) *BaseRepository[T] {
 }
 
 func (r *BaseRepository[T]) Create(ctx context.Context, entity *T) error {
-	return r.DB.WithContext(ctx).Create(entity).Error
+	return dbFromContext(ctx, r.DB).Create(entity).Error
 }
 
 func (r *BaseRepository[T]) Update(ctx context.Context, entity *T) error {
-	return r.DB.WithContext(ctx).Save(entity).Error
+	return dbFromContext(ctx, r.DB).Save(entity).Error
 }
 
 func (r *BaseRepository[T]) Delete(ctx context.Context, entity *T) error {
-	return r.DB.WithContext(ctx).Delete(entity).Error
+	return dbFromContext(ctx, r.DB).Delete(entity).Error
 }
 
 func (r *BaseRepository[T]) FindByID(ctx context.Context, id any) (*T, error) {
 	var entity T
-	err := r.DB.WithContext(ctx).
+	err := dbFromContext(ctx, r.DB).
 		Where("id = ?", id).
 		Take(&entity).Error
 	if err != nil {
@@ -39,17 +47,182 @@ func (r *BaseRepository[T]) FindByID(ctx context.Context, id any) (*T, error) {
 
 func (r *BaseRepository[T]) Count(ctx context.Context, query string, args ...any) (int64, error) {
 	var count int64
-	err := r.DB.WithContext(ctx).Model(new(T)).
+	err := dbFromContext(ctx, r.DB).Model(new(T)).
 		Where(query, args...).
 		Count(&count).Error
 	return count, err
 }
 
-func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, offset, limit int) (*[]T, error) {
+func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, page, size int) (*[]T, error) {
 	var entities []T
-	err := r.DB.WithContext(ctx).Offset(offset).Limit(limit).Find(&entities).Error
+	err := r.Paginate(dbFromContext(ctx, r.DB), page, size).Find(&entities).Error
 	if err != nil {
 		return nil, err
 	}
 	return &entities, nil
 }
+
+// Paginate applies page-number pagination, pages start at 1.
+func (r *BaseRepository[T]) Paginate(db *gorm.DB, page, size int) *gorm.DB {
+	if page < 1 {
+		page = 1
+	}
+	return db.Offset((page - 1) * size).Limit(size)
+}
+
+func (r *BaseRepository[T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.DB}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// keysetColumns appends the primary key to the requested sort so every row
+// has a unique position in the ordering.
+func keysetColumns(sorts []domain.SortKey) []domain.SortKey {
+	keys := make([]domain.SortKey, 0, len(sorts)+1)
+	keys = append(keys, sorts...)
+	return append(keys, domain.SortKey{Field: "id"})
+}
+
+// FindAllWithCursor runs db as a keyset page over table ordered by sorts.
+// It returns at most cursor.Limit rows in display order, and whether more
+// rows exist past the page in the direction it was read.
+func (r *BaseRepository[T]) FindAllWithCursor(db *gorm.DB, table string, sorts []domain.SortKey, cursor *domain.CursorPage) ([]T, bool, error) {
+	var entities []T
+
+	keys := keysetColumns(sorts)
+	if len(cursor.Values) > 0 {
+		if len(cursor.Values) != len(keys) {
+			return nil, false, util.ErrInvalidCursor
+		}
+
+		values, err := r.coerceCursorValues(keys, cursor.Values)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var (
+			ors  []string
+			args []any
+		)
+		for i, key := range keys {
+			var ands []string
+			for j := 0; j < i; j++ {
+				ands = append(ands, fmt.Sprintf("%q.%q = ?", table, keys[j].Field))
+				args = append(args, values[j])
+			}
+
+			op := ">"
+			if key.Desc != cursor.Backward {
+				op = "<"
+			}
+			ands = append(ands, fmt.Sprintf("%q.%q %s ?", table, key.Field, op))
+			args = append(args, values[i])
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+		db = db.Where(strings.Join(ors, " OR "), args...)
+	}
+
+	for _, key := range keys {
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: table, Name: key.Field},
+			Desc:   key.Desc != cursor.Backward,
+		})
+	}
+
+	if err := db.Limit(cursor.Limit + 1).Find(&entities).Error; err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(entities) > cursor.Limit
+	if hasMore {
+		entities = entities[:cursor.Limit]
+	}
+
+	if cursor.Backward {
+		for i, j := 0, len(entities)-1; i < j; i, j = i+1, j-1 {
+			entities[i], entities[j] = entities[j], entities[i]
+		}
+	}
+
+	return entities, hasMore, nil
+}
+
+// CursorValues reads the keyset position of entity for the given sort, in
+// the same order FindAllWithCursor expects them back.
+func (r *BaseRepository[T]) CursorValues(ctx context.Context, entity *T, sorts []domain.SortKey) ([]any, error) {
+	sch, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := keysetColumns(sorts)
+	values := make([]any, 0, len(keys))
+	for _, key := range keys {
+		field := sch.LookUpField(key.Field)
+		if field == nil {
+			return nil, fmt.Errorf("unknown cursor column %s", key.Field)
+		}
+
+		value, _ := field.ValueOf(ctx, reflect.ValueOf(entity).Elem())
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.RFC3339Nano)
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// coerceCursorValues converts values that went through JSON back into the
+// Go types of their columns.
+func (r *BaseRepository[T]) coerceCursorValues(keys []domain.SortKey, raw []any) ([]any, error) {
+	sch, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(raw))
+	for i, key := range keys {
+		field := sch.LookUpField(key.Field)
+		if field == nil {
+			return nil, util.ErrInvalidCursor
+		}
+
+		switch value := raw[i].(type) {
+		case string:
+			if field.FieldType == reflect.TypeOf(time.Time{}) {
+				t, err := time.Parse(time.RFC3339Nano, value)
+				if err != nil {
+					return nil, util.ErrInvalidCursor
+				}
+				values[i] = t
+				continue
+			}
+			if field.FieldType.Kind() != reflect.String {
+				return nil, util.ErrInvalidCursor
+			}
+			values[i] = value
+		case float64:
+			switch field.FieldType.Kind() {
+			case reflect.Int, reflect.Int32, reflect.Int64:
+				values[i] = int64(value)
+			case reflect.Uint, reflect.Uint32, reflect.Uint64:
+				values[i] = uint64(value)
+			default:
+				return nil, util.ErrInvalidCursor
+			}
+		case bool:
+			if field.FieldType.Kind() != reflect.Bool {
+				return nil, util.ErrInvalidCursor
+			}
+			values[i] = value
+		default:
+			return nil, util.ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
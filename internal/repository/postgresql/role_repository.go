@@ -0,0 +1,84 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type RoleRepository struct {
+	*BaseRepository[entity.Role]
+	DB *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) *RoleRepository {
+	return &RoleRepository{
+		BaseRepository: NewBaseRepository[entity.Role](db),
+		DB:             db,
+	}
+}
+
+func (r *RoleRepository) FindAllRole(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	if err := dbFromContext(ctx, r.DB).Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	var role entity.Role
+	if err := dbFromContext(ctx, r.DB).Where("name = ?", name).Take(&role).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) FindPermissionsByRoleID(ctx context.Context, roleID uint) ([]string, error) {
+	var permissions []string
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.RolePermission{}).
+		Joins("JOIN roles ON roles.id = role_permissions.role_id AND roles.deleted_at IS NULL").
+		Where("role_permissions.role_id = ?", roleID).
+		Pluck("role_permissions.permission", &permissions).Error
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *RoleRepository) AssignUser(ctx context.Context, roleID, userID uint) error {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.User{}).
+		Where("id = ?", userID).
+		Update("role_id", roleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *RoleRepository) UnassignUser(ctx context.Context, roleID, userID uint) error {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.User{}).
+		Where("id = ? AND role_id = ?", userID, roleID).
+		Update("role_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *RoleRepository) UnassignUsers(ctx context.Context, roleID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.User{}).
+		Where("role_id = ?", roleID).
+		Update("role_id", nil).Error
+}
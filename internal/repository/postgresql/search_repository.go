@@ -0,0 +1,132 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Snippets are HTML: the text is escaped before ts_headline marks it up, so
+// the only markup in a snippet is the <mark> around matches.
+const headlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5"
+
+type SearchRepository struct {
+	DB *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) *SearchRepository {
+	return &SearchRepository{DB: db}
+}
+
+// buildTsQuery turns user input into a to_tsquery expression. Bare words are
+// ANDed together, a trailing "*" makes a prefix match and double quoted text
+// becomes a phrase. Everything except letters and digits is dropped so the
+// result is always a syntactically valid tsquery.
+func buildTsQuery(input string) string {
+	var terms []string
+
+	for i, part := range strings.Split(input, `"`) {
+		if i%2 == 1 {
+			if words := tsLexemes(part); len(words) > 0 {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+			continue
+		}
+
+		for _, token := range strings.Fields(part) {
+			prefix := strings.HasSuffix(token, "*")
+			words := tsLexemes(token)
+			if len(words) == 0 {
+				continue
+			}
+			if prefix {
+				words[len(words)-1] += ":*"
+			}
+			if len(words) == 1 {
+				terms = append(terms, words[0])
+			} else {
+				terms = append(terms, "("+strings.Join(words, " <-> ")+")")
+			}
+		}
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+func tsLexemes(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = "'" + word + "'"
+	}
+	return words
+}
+
+func (r *SearchRepository) searchQuery(ctx context.Context, scope *domain.TodoScope, tsQuery, searchType string) *gorm.DB {
+	var (
+		parts []string
+		args  []any
+	)
+
+	if searchType == "" || searchType == domain.SearchTypeTodo {
+		todoSQL := `SELECT 'todo' AS type, todos.uuid, todos.title AS title,
+			ts_headline('english', replace(replace(replace(todos.title || ' ' || coalesce(todos.description, ''), '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), query, ?) AS snippet,
+			ts_rank(todos.search_vector, query) AS rank
+			FROM todos, to_tsquery('english', ?) query
+			WHERE todos.deleted_at IS NULL AND todos.search_vector @@ query`
+		args = append(args, headlineOptions, tsQuery)
+		if scope != nil && !scope.AllUsers {
+			todoSQL += " AND todos.user_id = ?"
+			args = append(args, scope.UserID)
+		}
+		parts = append(parts, todoSQL)
+	}
+
+	if searchType == "" || searchType == domain.SearchTypeTag {
+		parts = append(parts, `SELECT 'tag' AS type, tags.uuid, tags.name AS title,
+			ts_headline('english', replace(replace(replace(tags.name, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), query, ?) AS snippet,
+			ts_rank(tags.search_vector, query) AS rank
+			FROM tags, to_tsquery('english', ?) query
+			WHERE tags.deleted_at IS NULL AND tags.search_vector @@ query`)
+		args = append(args, headlineOptions, tsQuery)
+	}
+
+	return dbFromContext(ctx, r.DB).Raw(strings.Join(parts, " UNION ALL "), args...)
+}
+
+func (r *SearchRepository) Search(ctx context.Context, scope *domain.TodoScope, query, searchType string, offset, limit int) ([]entity.SearchResult, error) {
+	var results []entity.SearchResult
+	tsQuery := buildTsQuery(query)
+	if tsQuery == "" {
+		return results, nil
+	}
+
+	err := dbFromContext(ctx, r.DB).
+		Table("(?) AS results", r.searchQuery(ctx, scope, tsQuery, searchType)).
+		Order("rank DESC, type, uuid").
+		Offset(offset).
+		Limit(limit).
+		Find(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func (r *SearchRepository) Count(ctx context.Context, scope *domain.TodoScope, query, searchType string) (int64, error) {
+	var count int64
+	tsQuery := buildTsQuery(query)
+	if tsQuery == "" {
+		return 0, nil
+	}
+
+	err := dbFromContext(ctx, r.DB).
+		Table("(?) AS results", r.searchQuery(ctx, scope, tsQuery, searchType)).
+		Count(&count).Error
+	return count, err
+}
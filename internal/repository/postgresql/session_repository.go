@@ -0,0 +1,138 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SessionRepository struct {
+	*BaseRepository[entity.Session]
+	DB *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{
+		BaseRepository: NewBaseRepository[entity.Session](db),
+		DB:             db,
+	}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *entity.Session) error {
+	return dbFromContext(ctx, r.DB).Omit(clause.Associations).Create(session).Error
+}
+
+// FindActive returns the session with its user, provided neither the
+// session is revoked or expired nor the user deleted.
+func (r *SessionRepository) FindActive(ctx context.Context, id uuid.UUID, now time.Time) (*entity.Session, error) {
+	var session entity.Session
+	err := dbFromContext(ctx, r.DB).
+		InnerJoins("User").
+		Where("sessions.uuid = ? AND sessions.revoked_at IS NULL AND sessions.expires_at > ?", id, now).
+		Take(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) FindActiveByUserID(ctx context.Context, userID uint, now time.Time) ([]entity.Session, error) {
+	var sessions []entity.Session
+	err := dbFromContext(ctx, r.DB).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC, id DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error {
+	return dbFromContext(ctx, r.DB).Omit(clause.Associations).Create(token).Error
+}
+
+// FindRefreshToken locks the token with the given hash and loads its
+// session. It must be called inside a transaction.
+func (r *SessionRepository) FindRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	err := dbFromContext(ctx, r.DB).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		Take(&token).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = dbFromContext(ctx, r.DB).
+		Where("id = ?", token.SessionID).
+		Take(&token.Session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed reports false when the token was already used.
+func (r *SessionRepository) MarkRefreshTokenUsed(ctx context.Context, id uint64, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *SessionRepository) Extend(ctx context.Context, id uint64, usedAt, expiresAt time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"last_used_at": usedAt,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uint64, at time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at).Error
+}
+
+// RevokeByUUID revokes a session of userID and reports whether there was an
+// active one to revoke.
+func (r *SessionRepository) RevokeByUUID(ctx context.Context, userID uint, id uuid.UUID, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND uuid = ? AND revoked_at IS NULL", userID, id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *SessionRepository) RevokeAll(ctx context.Context, userID uint, at time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
+
+// RevokeOthers revokes every session of userID except keep.
+func (r *SessionRepository) RevokeOthers(ctx context.Context, userID uint, keep uuid.UUID, at time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Session{}).
+		Where("user_id = ? AND uuid <> ? AND revoked_at IS NULL", userID, keep).
+		Update("revoked_at", at).Error
+}
+
+// DeleteEndedBefore prunes sessions, and with them their refresh tokens,
+// that expired or were revoked before the given time.
+func (r *SessionRepository) DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.DB).
+		Where("expires_at < ? OR revoked_at < ?", before, before).
+		Delete(&entity.Session{})
+	return result.RowsAffected, result.Error
+}
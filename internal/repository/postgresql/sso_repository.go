@@ -0,0 +1,63 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SSORepository struct {
+	DB *gorm.DB
+}
+
+func NewSSORepository(db *gorm.DB) *SSORepository {
+	return &SSORepository{
+		DB: db,
+	}
+}
+
+func (r *SSORepository) CreateState(ctx context.Context, state *entity.SSOState) error {
+	return dbFromContext(ctx, r.DB).Create(state).Error
+}
+
+// TakeState deletes the state with the given hash and returns it, so each
+// state is only used once.
+func (r *SSORepository) TakeState(ctx context.Context, stateHash string) (*entity.SSOState, error) {
+	var states []entity.SSOState
+	err := dbFromContext(ctx, r.DB).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", stateHash).
+		Delete(&states).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(states) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &states[0], nil
+}
+
+func (r *SSORepository) DeleteStatesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.DB).
+		Where("expires_at < ?", before).
+		Delete(&entity.SSOState{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *SSORepository) FindIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := dbFromContext(ctx, r.DB).
+		Where("issuer = ? AND subject = ?", issuer, subject).
+		Take(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *SSORepository) SaveIdentity(ctx context.Context, identity *entity.UserIdentity) error {
+	return dbFromContext(ctx, r.DB).Save(identity).Error
+}
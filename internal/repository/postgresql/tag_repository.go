@@ -2,9 +2,12 @@ package postgresql
 
 import (
 	"context"
+	"go-todo-api/domain"
 	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TagRepository struct {
@@ -19,14 +22,53 @@ func NewTagRepository(db *gorm.DB) *TagRepository {
 	}
 }
 
-func (r *TagRepository) FindAllTag(ctx context.Context, offset, limit int) (*[]entity.Tag, error) {
+func (r *TagRepository) FindAllTag(ctx context.Context, page, size int) (*[]entity.Tag, error) {
 	var tags []entity.Tag
-	err := r.DB.WithContext(ctx).
-		Offset(offset).
-		Limit(limit).
+	err := r.Paginate(dbFromContext(ctx, r.DB), page, size).
+		Order("id").
 		Find(&tags).Error
 	if err != nil {
 		return nil, err
 	}
 	return &tags, nil
 }
+
+func (r *TagRepository) FindAllTagByCursor(ctx context.Context, cursor *domain.CursorPage) ([]entity.Tag, bool, error) {
+	return r.FindAllWithCursor(dbFromContext(ctx, r.DB), "tags", nil, cursor)
+}
+
+// UpdateTag writes tag back only if the row still has the version it was
+// read with, and bumps the version on success.
+func (r *TagRepository) UpdateTag(ctx context.Context, tag *entity.Tag) error {
+	version := tag.Version
+	tag.Version++
+
+	result := dbFromContext(ctx, r.DB).
+		Model(tag).
+		Where("tags.version = ?", version).
+		Select("*").
+		Omit(clause.Associations).
+		Updates(tag)
+	if result.Error != nil {
+		tag.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tag.Version = version
+		return util.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *TagRepository) DeleteTag(ctx context.Context, tag *entity.Tag) error {
+	result := dbFromContext(ctx, r.DB).
+		Where("tags.version = ?", tag.Version).
+		Delete(tag)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.ErrVersionConflict
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package postgresql
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
+
+// applyTodoFilter narrows a listing to top-level todos matching filter.
+// Subtasks are only listed under their parent.
+func applyTodoFilter(db *gorm.DB, filter *domain.TodoFilter) *gorm.DB {
+	db = db.Where("todos.parent_id IS NULL")
+	if filter == nil {
+		return db
+	}
+
+	if filter.IsCompleted != nil {
+		db = db.Where("todos.is_completed = ?", *filter.IsCompleted)
+	}
+	if filter.DueAfter != nil {
+		db = db.Where("todos.due_time >= ?", *filter.DueAfter)
+	}
+	if filter.DueBefore != nil {
+		db = db.Where("todos.due_time < ?", *filter.DueBefore)
+	}
+	if filter.Overdue {
+		db = db.Where("todos.due_time < ? AND todos.is_completed = FALSE", time.Now())
+	}
+	if filter.DueToday {
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		db = db.Where("todos.due_time >= ? AND todos.due_time < ?", startOfDay, startOfDay.AddDate(0, 0, 1))
+	}
+	if filter.Title != "" {
+		db = db.Where(`todos.title ILIKE ? ESCAPE '\'`, containsPattern(filter.Title))
+	}
+	if filter.Description != "" {
+		db = db.Where(`todos.description ILIKE ? ESCAPE '\'`, containsPattern(filter.Description))
+	}
+	if filter.CreatedAfter != nil {
+		db = db.Where("todos.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		db = db.Where("todos.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.UpdatedAfter != nil {
+		db = db.Where("todos.updated_at >= ?", *filter.UpdatedAfter)
+	}
+	if filter.UpdatedBefore != nil {
+		db = db.Where("todos.updated_at < ?", *filter.UpdatedBefore)
+	}
+
+	if len(filter.TagUUIDs) > 0 {
+		taggedTodos := db.Session(&gorm.Session{NewDB: true}).
+			Model(&entity.TodoTag{}).
+			Select("todo_tags.todo_id").
+			Joins("JOIN tags ON tags.id = todo_tags.tag_id AND tags.deleted_at IS NULL").
+			Where("tags.uuid IN ?", filter.TagUUIDs)
+		if filter.TagMatchAll {
+			taggedTodos = taggedTodos.
+				Group("todo_tags.todo_id").
+				Having("COUNT(DISTINCT tags.id) = ?", len(filter.TagUUIDs))
+		}
+		db = db.Where("todos.id IN (?)", taggedTodos)
+	}
+
+	return db
+}
+
+func applyTodoSort(db *gorm.DB, sorts []domain.SortKey) *gorm.DB {
+	for _, sort := range sorts {
+		if !domain.TodoSortableFields[sort.Field] {
+			continue
+		}
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: "todos", Name: sort.Field},
+			Desc:   sort.Desc,
+		})
+	}
+	return db.Order("todos.id")
+}
@@ -2,9 +2,13 @@ package postgresql
 
 import (
 	"context"
+	"go-todo-api/domain"
 	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TodoRepository struct {
@@ -19,11 +23,22 @@ func NewTodoRepository(db *gorm.DB) *TodoRepository {
 	}
 }
 
-func (r *TodoRepository) FindAll(ctx context.Context, offset, limit int) (*[]entity.Todo, error) {
+func (r *TodoRepository) scoped(ctx context.Context, scope *domain.TodoScope) *gorm.DB {
+	db := dbFromContext(ctx, r.DB)
+	if scope != nil && !scope.AllUsers {
+		db = db.Where("todos.user_id = ?", scope.UserID)
+	}
+	return db
+}
+
+func (r *TodoRepository) scopedTodoIDs(ctx context.Context, scope *domain.TodoScope) *gorm.DB {
+	return r.scoped(ctx, scope).Model(&entity.Todo{}).Select("todos.id")
+}
+
+func (r *TodoRepository) FindAll(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter, sorts []domain.SortKey, page, size int) (*[]entity.Todo, error) {
 	var todos []entity.Todo
-	err := r.DB.WithContext(ctx).
-		Offset(offset).
-		Limit(limit).
+	db := applyTodoSort(applyTodoFilter(r.scoped(ctx, scope), filter), sorts)
+	err := r.Paginate(db, page, size).
 		Preload("Tag").
 		Find(&todos).Error
 	if err != nil {
@@ -32,29 +47,100 @@ func (r *TodoRepository) FindAll(ctx context.Context, offset, limit int) (*[]ent
 	return &todos, nil
 }
 
+func (r *TodoRepository) FindAllByCursor(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter, sorts []domain.SortKey, cursor *domain.CursorPage) ([]entity.Todo, bool, error) {
+	db := applyTodoFilter(r.scoped(ctx, scope), filter).Preload("Tag")
+	return r.FindAllWithCursor(db, "todos", sorts, cursor)
+}
+
+func (r *TodoRepository) CountTodo(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter) (int64, error) {
+	var count int64
+	err := applyTodoFilter(r.scoped(ctx, scope), filter).Model(&entity.Todo{}).Count(&count).Error
+	return count, err
+}
+
+func (r *TodoRepository) FindTodoByID(ctx context.Context, scope *domain.TodoScope, id any) (*entity.Todo, error) {
+	var todo entity.Todo
+	err := r.scoped(ctx, scope).
+		Where("todos.id = ?", id).
+		Take(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+// UpdateTodo writes todo back only if the row still has the version it was
+// read with, and bumps the version on success. Columns owned by background
+// jobs are left alone.
+func (r *TodoRepository) UpdateTodo(ctx context.Context, scope *domain.TodoScope, todo *entity.Todo) error {
+	version := todo.Version
+	todo.Version++
+
+	result := r.scoped(ctx, scope).
+		Model(todo).
+		Where("todos.version = ?", version).
+		Select("*").
+		Omit(clause.Associations, "next_occurrence_id", "overdue_notified_at").
+		Updates(todo)
+	if result.Error != nil {
+		todo.Version = version
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		todo.Version = version
+		return util.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *TodoRepository) DeleteTodo(ctx context.Context, scope *domain.TodoScope, todo *entity.Todo) error {
+	result := r.scoped(ctx, scope).
+		Where("todos.version = ?", todo.Version).
+		Delete(todo)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return util.ErrVersionConflict
+	}
+	return nil
+}
+
 func (r *TodoRepository) CreateTodoTag(ctx context.Context, todoTag *entity.TodoTag) error {
-	return r.DB.WithContext(ctx).Create(todoTag).Error
+	return dbFromContext(ctx, r.DB).Create(todoTag).Error
 }
 
-func (r *TodoRepository) FindTodoTagByTodoID(ctx context.Context, todoID uint) ([]entity.TodoTag, error) {
+func (r *TodoRepository) FindTodoTagByTodoID(ctx context.Context, scope *domain.TodoScope, todoID uint) ([]entity.TodoTag, error) {
 	var todoTags []entity.TodoTag
-	if err := r.DB.WithContext(ctx).Where("todo_id = ?", todoID).Find(&todoTags).Error; err != nil {
+	err := dbFromContext(ctx, r.DB).
+		Where("todo_id = ?", todoID).
+		Where("todo_id IN (?)", r.scopedTodoIDs(ctx, scope)).
+		Find(&todoTags).Error
+	if err != nil {
 		return nil, err
 	}
 	return todoTags, nil
 }
 
-func (r *TodoRepository) FindTodoTagByTagID(ctx context.Context, tagID uint) ([]entity.TodoTag, error) {
+func (r *TodoRepository) FindTodoTag(ctx context.Context, scope *domain.TodoScope, todoID, tagID uint) ([]entity.TodoTag, error) {
 	var todoTags []entity.TodoTag
-	if err := r.DB.WithContext(ctx).Where("tag_id = ?", tagID).Find(&todoTags).Error; err != nil {
+	err := dbFromContext(ctx, r.DB).
+		Where("todo_id = ? AND tag_id = ?", todoID, tagID).
+		Where("todo_id IN (?)", r.scopedTodoIDs(ctx, scope)).
+		Find(&todoTags).Error
+	if err != nil {
 		return nil, err
 	}
 	return todoTags, nil
 }
 
-func (r *TodoRepository) DeleteTodoTag(ctx context.Context, todoTags []entity.TodoTag) error {
+func (r *TodoRepository) DeleteTodoTag(ctx context.Context, scope *domain.TodoScope, todoTags []entity.TodoTag) error {
 	for _, todoTag := range todoTags {
-		if err := r.DB.WithContext(ctx).Unscoped().Delete(&todoTag).Error; err != nil {
+		err := dbFromContext(ctx, r.DB).
+			Unscoped().
+			Where("todo_id IN (?)", r.scopedTodoIDs(ctx, scope)).
+			Delete(&todoTag).Error
+		if err != nil {
 			return err
 		}
 	}
@@ -63,8 +149,158 @@ func (r *TodoRepository) DeleteTodoTag(ctx context.Context, todoTags []entity.To
 
 func (r *TodoRepository) FindUserById(ctx context.Context, id any) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Where("id = ?", id).First(&user).Error; err != nil {
+	if err := dbFromContext(ctx, r.DB).Where("id = ?", id).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+func (r *TodoRepository) FindTagsByTodoID(ctx context.Context, todoID uint) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	err := dbFromContext(ctx, r.DB).
+		Joins("JOIN todo_tags ON todo_tags.tag_id = tags.id AND todo_tags.deleted_at IS NULL").
+		Where("todo_tags.todo_id = ?", todoID).
+		Order("tags.id").
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TodoRepository) FindTagsByUUIDs(ctx context.Context, uuids []uuid.UUID) ([]entity.Tag, error) {
+	var tags []entity.Tag
+	if len(uuids) == 0 {
+		return tags, nil
+	}
+	err := dbFromContext(ctx, r.DB).
+		Where("uuid IN ?", uuids).
+		Find(&tags).Error
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *TodoRepository) FindSubtasks(ctx context.Context, scope *domain.TodoScope, parentID uint) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := r.scoped(ctx, scope).
+		Where("todos.parent_id = ?", parentID).
+		Order("todos.position").
+		Order("todos.id").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (r *TodoRepository) FindSubtaskByUUID(ctx context.Context, scope *domain.TodoScope, parentID uint, id uuid.UUID) (*entity.Todo, error) {
+	var todo entity.Todo
+	err := r.scoped(ctx, scope).
+		Where("todos.parent_id = ? AND todos.uuid = ?", parentID, id).
+		Take(&todo).Error
+	if err != nil {
+		return nil, err
+	}
+	return &todo, nil
+}
+
+func (r *TodoRepository) NextSubtaskPosition(ctx context.Context, parentID uint) (int, error) {
+	var position int
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("parent_id = ?", parentID).
+		Select("COALESCE(MAX(position), 0) + 1").
+		Scan(&position).Error
+	return position, err
+}
+
+func (r *TodoRepository) SetSubtaskPosition(ctx context.Context, id uint, position int) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"position": position,
+			"version":  gorm.Expr("version + 1"),
+		}).Error
+}
+
+// CompleteSubtasks marks every open subtask of parentID as completed.
+func (r *TodoRepository) CompleteSubtasks(ctx context.Context, parentID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("parent_id = ? AND is_completed = FALSE", parentID).
+		Updates(map[string]any{
+			"is_completed": true,
+			"version":      gorm.Expr("version + 1"),
+		}).Error
+}
+
+func (r *TodoRepository) DeleteSubtasks(ctx context.Context, parentID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Where("parent_id = ?", parentID).
+		Delete(&entity.Todo{}).Error
+}
+
+// TouchTodo bumps the version of a todo whose subtasks changed, so its ETag
+// reflects the new roll-up, and optionally reopens it.
+func (r *TodoRepository) TouchTodo(ctx context.Context, id uint, reopen bool) error {
+	updates := map[string]any{"version": gorm.Expr("version + 1")}
+	if reopen {
+		updates["is_completed"] = false
+	}
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+func (r *TodoRepository) SubtaskProgress(ctx context.Context, parentIDs []uint) (map[uint]domain.TodoProgress, error) {
+	var rows []struct {
+		ParentID  uint
+		Total     int64
+		Completed int64
+	}
+
+	progress := make(map[uint]domain.TodoProgress, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return progress, nil
+	}
+
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE is_completed) AS completed").
+		Where("parent_id IN ?", parentIDs).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		progress[row.ParentID] = domain.TodoProgress{Total: row.Total, Completed: row.Completed}
+	}
+	return progress, nil
+}
+
+// FindRecurring returns the open recurring todos, which are the live
+// occurrence of each series.
+func (r *TodoRepository) FindRecurring(ctx context.Context, scope *domain.TodoScope) ([]entity.Todo, error) {
+	var todos []entity.Todo
+	err := r.scoped(ctx, scope).
+		Where("todos.recurrence <> '' AND todos.is_completed = FALSE").
+		Order("todos.due_time").
+		Find(&todos).Error
+	if err != nil {
+		return nil, err
+	}
+	return todos, nil
+}
+
+func (r *TodoRepository) SetNextOccurrence(ctx context.Context, id, nextID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.Todo{}).
+		Where("id = ?", id).
+		UpdateColumn("next_occurrence_id", nextID).Error
+}
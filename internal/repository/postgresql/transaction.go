@@ -0,0 +1,41 @@
+package postgresql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// TransactionManager runs a unit of work inside one database transaction.
+// The transaction travels in the context, so every repository called with
+// that context joins it instead of using the root connection.
+type TransactionManager struct {
+	DB *gorm.DB
+}
+
+func NewTransactionManager(db *gorm.DB) *TransactionManager {
+	return &TransactionManager{DB: db}
+}
+
+// WithinTx commits when fn returns nil and rolls back when it returns an
+// error or panics. Nested calls reuse the outer transaction.
+func (m *TransactionManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	return m.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// dbFromContext returns the transaction stored in ctx, or db when the call
+// is not part of a unit of work.
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,148 @@
+package postgresql
+
+import (
+	"context"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TwoFactorRepository struct {
+	DB *gorm.DB
+}
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		DB: db,
+	}
+}
+
+// ReplaceRecoveryCodes drops every recovery code of userID, used or not,
+// and stores the given hashes instead.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error {
+	if err := r.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return err
+	}
+
+	codes := make([]entity.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, entity.RecoveryCode{UserID: userID, CodeHash: hash})
+	}
+	return dbFromContext(ctx, r.DB).Create(&codes).Error
+}
+
+func (r *TwoFactorRepository) DeleteRecoveryCodes(ctx context.Context, userID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Where("user_id = ?", userID).
+		Delete(&entity.RecoveryCode{}).Error
+}
+
+// UseRecoveryCode marks the unused code of userID with the given hash as
+// used and reports whether there was one.
+func (r *TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseTOTPStep records step as the last TOTP step used by userID and reports
+// false if that step or a later one was already used.
+func (r *TwoFactorRepository) UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error) {
+	result := dbFromContext(ctx, r.DB).
+		Model(&entity.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *TwoFactorRepository) CreateLoginChallenge(ctx context.Context, challenge *entity.LoginChallenge) error {
+	return dbFromContext(ctx, r.DB).Create(challenge).Error
+}
+
+// FindLoginChallenge locks the challenge with the given hash. It must be
+// called inside a transaction.
+func (r *TwoFactorRepository) FindLoginChallenge(ctx context.Context, tokenHash string) (*entity.LoginChallenge, error) {
+	var challenge entity.LoginChallenge
+	err := dbFromContext(ctx, r.DB).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		Take(&challenge).Error
+	if err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *TwoFactorRepository) UpdateLoginChallenge(ctx context.Context, challenge *entity.LoginChallenge) error {
+	return dbFromContext(ctx, r.DB).Save(challenge).Error
+}
+
+func (r *TwoFactorRepository) DeleteLoginChallenges(ctx context.Context, userID uint) error {
+	return dbFromContext(ctx, r.DB).
+		Where("user_id = ?", userID).
+		Delete(&entity.LoginChallenge{}).Error
+}
+
+func (r *TwoFactorRepository) DeleteLoginChallengesBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.DB).
+		Where("expires_at < ?", before).
+		Delete(&entity.LoginChallenge{})
+	return result.RowsAffected, result.Error
+}
+
+// CountFailedAttemptsSince sums the wrong codes sent for the challenges of
+// userID created since since that did not end in a login.
+func (r *TwoFactorRepository) CountFailedAttemptsSince(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	var failed int64
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.LoginChallenge{}).
+		Where("user_id = ? AND created_at >= ? AND used_at IS NULL", userID, since).
+		Select("COALESCE(SUM(attempts), 0)").
+		Scan(&failed).Error
+	return failed, err
+}
+
+// RequiresTwoFactor reports whether a policy requires two-factor
+// authentication of users with the built-in role.
+func (r *TwoFactorRepository) RequiresTwoFactor(ctx context.Context, role string) (bool, error) {
+	var count int64
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.TwoFactorPolicy{}).
+		Where("role = ?", role).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TwoFactorRepository) FindTwoFactorRoles(ctx context.Context) ([]string, error) {
+	var roles []string
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.TwoFactorPolicy{}).
+		Order("role").
+		Pluck("role", &roles).Error
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// ReplaceTwoFactorRoles makes roles the only built-in roles that require
+// two-factor authentication.
+func (r *TwoFactorRepository) ReplaceTwoFactorRoles(ctx context.Context, roles []string) error {
+	db := dbFromContext(ctx, r.DB)
+	if err := db.Where("1 = 1").Delete(&entity.TwoFactorPolicy{}).Error; err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+
+	policies := make([]entity.TwoFactorPolicy, 0, len(roles))
+	for _, role := range roles {
+		policies = append(policies, entity.TwoFactorPolicy{Role: role})
+	}
+	return db.Create(&policies).Error
+}
@@ -3,8 +3,10 @@ package postgresql
 import (
 	"context"
 	"go-todo-api/internal/entity"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository struct {
@@ -21,13 +23,13 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 
 func (r *UserRepository) CountByEmailOrName(ctx context.Context, user *entity.User) (int64, error) {
 	var count int64
-	err := r.DB.WithContext(ctx).Model(&entity.User{}).Where("email = ? OR name = ? ", user.Email, user.Name).Count(&count).Error
+	err := dbFromContext(ctx, r.DB).Model(&entity.User{}).Where("email = ? OR name = ? ", user.Email, user.Name).Count(&count).Error
 	return count, err
 }
 
 func (r *UserRepository) FindByEmailOrName(ctx context.Context, email string, name string) (*entity.User, error) {
 	var user entity.User
-	if err := r.DB.WithContext(ctx).Where("email = ? OR name = ?", email, name).First(&user).Error; err != nil {
+	if err := dbFromContext(ctx, r.DB).Where("email = ? OR name = ?", email, name).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -35,9 +37,65 @@ func (r *UserRepository) FindByEmailOrName(ctx context.Context, email string, na
 
 func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.User, error) {
 	var user entity.User
-	err := r.DB.WithContext(ctx).Where("uuid = ? ", uuid).First(&user).Error
+	err := dbFromContext(ctx, r.DB).Where("uuid = ? ", uuid).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
+
+func (r *UserRepository) FindPreference(ctx context.Context, userID uint) (*entity.UserPreference, error) {
+	var preference entity.UserPreference
+	if err := dbFromContext(ctx, r.DB).Where("user_id = ?", userID).Take(&preference).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *UserRepository) SavePreference(ctx context.Context, preference *entity.UserPreference) error {
+	return dbFromContext(ctx, r.DB).Omit(clause.Associations).Save(preference).Error
+}
+
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	var user entity.User
+	if err := dbFromContext(ctx, r.DB).Where("LOWER(email) = LOWER(?)", email).Take(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) CreatePasswordReset(ctx context.Context, reset *entity.PasswordReset) error {
+	return dbFromContext(ctx, r.DB).Create(reset).Error
+}
+
+func (r *UserRepository) CountPasswordResetsSince(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.DB).
+		Model(&entity.PasswordReset{}).
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Count(&count).Error
+	return count, err
+}
+
+// FindPasswordReset locks the reset with the given token hash. It must be
+// called inside a transaction.
+func (r *UserRepository) FindPasswordReset(ctx context.Context, tokenHash string) (*entity.PasswordReset, error) {
+	var reset entity.PasswordReset
+	err := dbFromContext(ctx, r.DB).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token_hash = ?", tokenHash).
+		Take(&reset).Error
+	if err != nil {
+		return nil, err
+	}
+	return &reset, nil
+}
+
+// ConsumePasswordResets marks every unused reset of userID used, so that
+// once one link has worked the others stop working too.
+func (r *UserRepository) ConsumePasswordResets(ctx context.Context, userID uint, at time.Time) error {
+	return dbFromContext(ctx, r.DB).
+		Model(&entity.PasswordReset{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", at).Error
+}
@@ -0,0 +1,41 @@
+package rest
+
+import (
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/util"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isAtomic reports whether a bulk request asked for all-or-nothing
+// processing with ?atomic=true.
+func isAtomic(c *gin.Context) bool {
+	atomic, _ := strconv.ParseBool(c.Query("atomic"))
+	return atomic
+}
+
+func invalidItem(index int, err error) error {
+	return util.NewItemError(index, util.NewCustomError(int(util.ErrBadRequestCode), err.Error()))
+}
+
+// abortWithItemErrors reports why an atomic batch was rejected, keyed by the
+// position of each failing item in the request array.
+func abortWithItemErrors(c *gin.Context, status int, errs []error) {
+	reports := make([]domain.BulkItemError, 0, len(errs))
+	for _, err := range errs {
+		report := domain.BulkItemError{Index: -1, Message: err.Error()}
+		var itemErr *util.ItemError
+		if errors.As(err, &itemErr) {
+			report.Index = itemErr.Index
+		}
+		reports = append(reports, report)
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"status":  false,
+		"message": "Batch rolled back, no items were applied",
+		"errors":  reports,
+	})
+}
@@ -0,0 +1,140 @@
+package rest
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+type DeadLetterUsecase interface {
+	FindAll(ctx context.Context, request *domain.DeadLetterListRequest) ([]*domain.DeadLetterResponse, *domain.PaginationMeta, error)
+	FindByUUID(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error)
+	Retry(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error)
+	Discard(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error)
+}
+
+type DeadLetterHandler struct {
+	Log     *logrus.Logger
+	UseCase DeadLetterUsecase
+}
+
+func NewDeadLetterHandler(r *gin.Engine, u DeadLetterUsecase, log *logrus.Logger, permission *middleware.Permission) {
+	handler := &DeadLetterHandler{
+		UseCase: u,
+		Log:     log,
+	}
+
+	manage := permission.Require(domain.PermUserManage)
+	r.GET("v1/dead_letters", manage, handler.FindAll)
+	r.GET("v1/dead_letters/:id", manage, handler.FindByUUID)
+	r.POST("v1/dead_letters/:id/_retry", manage, handler.Retry)
+	r.DELETE("v1/dead_letters/:id", manage, handler.Discard)
+}
+
+func (h *DeadLetterHandler) FindAll(c *gin.Context) {
+	page, size, err := parsePageQuery(c)
+	if err != nil {
+		h.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	responses, meta, err := h.UseCase.FindAll(c, &domain.DeadLetterListRequest{
+		Page:    page,
+		Size:    size,
+		JobName: c.Query("job_name"),
+	})
+	if err != nil {
+		h.Log.WithError(err).Error("Error find dead letters")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.DeadLetterResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Dead letters retrieved successfully",
+		Data:       responses,
+		Meta:       meta,
+	})
+}
+
+func (h *DeadLetterHandler) FindByUUID(c *gin.Context) {
+	request, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.UseCase.FindByUUID(c, request)
+	if err != nil {
+		h.Log.WithError(err).Error("Error find dead letter")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.DeadLetterResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Dead letter retrieved successfully",
+		Data:       response,
+	})
+}
+
+func (h *DeadLetterHandler) Retry(c *gin.Context) {
+	request, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.UseCase.Retry(c, request)
+	if err != nil {
+		h.Log.WithError(err).Error("Error retry dead letter")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, domain.Response[*domain.DeadLetterResponse]{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "Dead letter queued for retry",
+		Data:       response,
+	})
+}
+
+func (h *DeadLetterHandler) Discard(c *gin.Context) {
+	request, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.UseCase.Discard(c, request)
+	if err != nil {
+		h.Log.WithError(err).Error("Error discard dead letter")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.DeadLetterResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Dead letter discarded successfully",
+		Data:       response,
+	})
+}
+
+func (h *DeadLetterHandler) bindRequest(c *gin.Context) (*domain.DeadLetterRequest, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "invalid dead letter id"})
+		return nil, false
+	}
+
+	return &domain.DeadLetterRequest{UUID: id}, true
+}
@@ -0,0 +1,61 @@
+package rest
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type EmailUsecase interface {
+	Preview(ctx context.Context, auth *entity.User, request *domain.EmailPreviewRequest) (*domain.EmailPreviewResponse, error)
+}
+
+type EmailHandler struct {
+	Log     *logrus.Logger
+	UseCase EmailUsecase
+}
+
+func NewEmailHandler(r *gin.Engine, u EmailUsecase, log *logrus.Logger, permission *middleware.Permission) {
+	handler := &EmailHandler{
+		UseCase: u,
+		Log:     log,
+	}
+
+	r.GET("v1/emails/:kind/_preview", permission.Require(domain.PermUserManage), handler.Preview)
+}
+
+// Preview renders an email template with sample data. ?format=html or
+// ?format=text returns the rendered body as is, for viewing in a browser.
+func (h *EmailHandler) Preview(c *gin.Context) {
+	request := &domain.EmailPreviewRequest{
+		Kind:   c.Param("kind"),
+		Locale: c.Query("locale"),
+	}
+
+	response, err := h.UseCase.Preview(c, middleware.GetUser(c), request)
+	if err != nil {
+		h.Log.WithError(err).Error("Error previewing email")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	switch c.Query("format") {
+	case "html":
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(response.HTML))
+	case "text":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(response.Text))
+	default:
+		c.JSON(http.StatusOK, domain.Response[*domain.EmailPreviewResponse]{
+			Status:     true,
+			StatusCode: http.StatusOK,
+			Message:    "Email preview rendered successfully",
+			Data:       response,
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errPreconditionRequired = errors.New("If-Match header is required")
+
+func formatETag(version uint) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// ifMatchVersion reads the version the client expects from If-Match. A
+// missing header or "*" yields nil, since the lookup already guarantees the
+// resource exists. Weak tags are rejected because If-Match compares strongly.
+func ifMatchVersion(c *gin.Context, required bool) (*uint, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		if required {
+			return nil, errPreconditionRequired
+		}
+		return nil, nil
+	}
+	if header == "*" {
+		return nil, nil
+	}
+
+	if strings.Contains(header, ",") || strings.HasPrefix(header, "W/") {
+		return nil, fmt.Errorf("If-Match must be a single strong ETag")
+	}
+
+	version, err := strconv.ParseUint(strings.Trim(header, `"`), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ETag in If-Match: %s", header)
+	}
+
+	v := uint(version)
+	return &v, nil
+}
+
+func abortWithPreconditionError(c *gin.Context, err error) {
+	status := http.StatusBadRequest
+	if errors.Is(err, errPreconditionRequired) {
+		status = http.StatusPreconditionRequired
+	}
+	c.AbortWithStatusJSON(status, gin.H{"errors": err.Error()})
+}
+
+// notModified sets the ETag header and reports whether If-None-Match already
+// holds it, using the weak comparison RFC 9110 prescribes for GET.
+func notModified(c *gin.Context, etag string) bool {
+	c.Header("ETag", etag)
+
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
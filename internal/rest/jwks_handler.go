@@ -0,0 +1,29 @@
+package rest
+
+import (
+	"go-todo-api/internal/config"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeySetProvider interface {
+	JWKS() config.JWKS
+}
+
+type JWKSHandler struct {
+	Keys KeySetProvider
+}
+
+// NewJWKSHandler publishes the token verification keys. The response is a
+// plain JWK set, as verifiers expect, rather than the usual envelope.
+func NewJWKSHandler(r *gin.Engine, keys KeySetProvider) {
+	handler := &JWKSHandler{Keys: keys}
+
+	r.GET("/.well-known/jwks.json", handler.JWKS)
+}
+
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.Keys.JWKS())
+}
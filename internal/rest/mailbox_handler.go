@@ -0,0 +1,63 @@
+package rest
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/mailer"
+	"go-todo-api/internal/rest/middleware"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// MailboxHandler lists the emails captured by the file or memory transport.
+// It is only registered when DEV_MAILBOX is set, and as the emails hold
+// every user's reset and verification links, only user managers may read
+// them.
+type MailboxHandler struct {
+	Log     *logrus.Logger
+	Mailbox mailer.Mailbox
+}
+
+func NewMailboxHandler(r *gin.Engine, mailbox mailer.Mailbox, log *logrus.Logger, permission *middleware.Permission) {
+	handler := &MailboxHandler{
+		Mailbox: mailbox,
+		Log:     log,
+	}
+
+	r.GET("v1/dev/mailbox", permission.Require(domain.PermUserManage), handler.FindAll)
+}
+
+// FindAll returns captured messages, newest first, optionally only those
+// sent to ?to=.
+func (h *MailboxHandler) FindAll(c *gin.Context) {
+	messages, err := h.Mailbox.Messages()
+	if err != nil {
+		h.Log.WithError(err).Error("Error reading mailbox")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"errors": err.Error()})
+		return
+	}
+
+	to := c.Query("to")
+	responses := make([]*domain.MailboxMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		if to != "" && !strings.EqualFold(message.To, to) {
+			continue
+		}
+		responses = append(responses, &domain.MailboxMessageResponse{
+			To:      message.To,
+			Subject: message.Subject,
+			HTML:    message.HTML,
+			Text:    message.Text,
+			SentAt:  message.SentAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.MailboxMessageResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Mailbox messages retrieved successfully",
+		Data:       responses,
+	})
+}
@@ -2,18 +2,39 @@ package middleware
 
 import (
 	"go-todo-api/domain"
-	"go-todo-api/internal/config"
 	"go-todo-api/internal/entity"
 	"go-todo-api/internal/usecase"
+	"go-todo-api/internal/util"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// NewAuth authenticates requests with an access token in the Authorization
+// header as "Bearer <token>", or with an API key either in the X-API-Key
+// header or in the Authorization header as "ApiKey <key>".
 func NewAuth(userUseCase *usecase.UserUsecase) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.GetHeader("Authorization")
+		apiKey := ctx.GetHeader("X-API-Key")
+		if apiKey == "" && strings.HasPrefix(authHeader, "ApiKey ") {
+			apiKey = strings.TrimSpace(strings.TrimPrefix(authHeader, "ApiKey "))
+		}
+		if apiKey != "" {
+			user, err := userUseCase.AuthenticateAPIKey(ctx, apiKey)
+			if err != nil {
+				userUseCase.Log.Warnf("Failed to validate API key: %+v", err)
+				ctx.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+				return
+			}
+
+			ctx.Set("auth", user)
+			ctx.Next()
+			return
+		}
+
 		if authHeader == "" {
 			userUseCase.Log.Warn("Failed to get user by token")
 			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "Token is missing"})
@@ -29,34 +50,44 @@ func NewAuth(userUseCase *usecase.UserUsecase) gin.HandlerFunc {
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 		token = strings.TrimSpace(token)
 
-		userUseCase.Log.Debugf("Authorization: %s", token)
-
-		jwt, _ := config.InitJwtService()
-		claims, err := jwt.ValidateToken(token)
+		user, session, err := userUseCase.Authenticate(ctx, token)
 		if err != nil {
 			userUseCase.Log.Warnf("Failed to validate user token: %+v", err)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": err.Error()})
+			ctx.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
 			return
 		}
 
-		getUserIdRequest := &domain.GetUserId{
-			ID: claims.UserID,
-		}
+		ctx.Set("auth", user)
+		ctx.Set("session", session)
 
-		user, err := userUseCase.GetUserID(ctx, getUserIdRequest)
-		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "User not found"})
+		ctx.Next()
+	}
+}
+
+// RequireSession refuses requests authenticated with an API key. It guards
+// routes that manage the account itself, which a key must not be able to
+// reach whatever its scopes.
+func RequireSession() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if GetSession(ctx) == nil {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": "This endpoint requires signing in, API keys are not accepted"})
 			return
 		}
 
-		if user.Token == "" || user.Token != token {
-			userUseCase.Log.Warnf("Failed to validate user token: %+v", err)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "Token invalid or missing"})
+		ctx.Next()
+	}
+}
+
+// RequireSessionOrScope lets API keys through only if scope is among their
+// scopes. It guards routes that read the account, which signed-in users may
+// always do.
+func RequireSessionOrScope(scope domain.Permission) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if GetSession(ctx) == nil && !slices.Contains(GetUser(ctx).Scopes, string(scope)) {
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errors": "API key is missing the " + string(scope) + " scope"})
 			return
 		}
 
-		ctx.Set("auth", user)
-
 		ctx.Next()
 	}
 }
@@ -67,3 +98,11 @@ func GetUser(ctx *gin.Context) *entity.User {
 	}
 	return nil
 }
+
+// GetSession returns the session the request was authenticated with.
+func GetSession(ctx *gin.Context) *entity.Session {
+	if session, exists := ctx.Get("session"); exists {
+		return session.(*entity.Session)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+
+	idempotencyPending = "pending"
+	idempotencyDone    = "done"
+)
+
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+type Idempotency struct {
+	Pool *redis.Pool
+	TTL  time.Duration
+	Log  *logrus.Logger
+}
+
+func NewIdempotency(pool *redis.Pool, ttl time.Duration, log *logrus.Logger) *Idempotency {
+	return &Idempotency{
+		Pool: pool,
+		TTL:  ttl,
+		Log:  log,
+	}
+}
+
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Handle stores the first response for an Idempotency-Key per user and
+// replays it for retries of the same request. Reusing a key for a different
+// request is rejected with 422. Redis failures never block the request.
+func (i *Idempotency) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		user := GetUser(c)
+		if key == "" || user == nil || !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "Idempotency-Key is too long"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "Failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		fmt.Fprintf(hash, "%s\n%s\n", c.Request.Method, c.Request.URL.RequestURI())
+		hash.Write(body)
+		fingerprint := hex.EncodeToString(hash.Sum(nil))
+
+		keyHash := sha256.Sum256([]byte(key))
+		redisKey := fmt.Sprintf("idempotency:%d:%s", user.ID, hex.EncodeToString(keyHash[:]))
+
+		pending, _ := json.Marshal(idempotencyRecord{State: idempotencyPending, Fingerprint: fingerprint})
+		reply, err := redis.String(i.do("SET", redisKey, pending, "NX", "EX", int(i.TTL.Seconds())))
+		if err != nil && err != redis.ErrNil {
+			i.Log.WithError(err).Warn("Idempotency store unavailable, processing request without it")
+			c.Next()
+			return
+		}
+
+		if reply != "OK" {
+			i.replay(c, redisKey, fingerprint)
+			return
+		}
+
+		// A panicking handler must not leave the key pending until it
+		// expires, so it is released before the panic reaches Recovery.
+		defer func() {
+			if r := recover(); r != nil {
+				i.release(redisKey)
+				panic(r)
+			}
+		}()
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			i.release(redisKey)
+			return
+		}
+
+		done, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyDone,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		if _, err := i.do("SET", redisKey, done, "EX", int(i.TTL.Seconds())); err != nil {
+			i.Log.WithError(err).Warn("Failed to store idempotent response")
+		}
+	}
+}
+
+// do runs a single command on a pooled connection. Connections are not held
+// while the request is handled, so slow handlers cannot drain the pool.
+func (i *Idempotency) do(command string, args ...any) (any, error) {
+	conn := i.Pool.Get()
+	defer conn.Close()
+	return conn.Do(command, args...)
+}
+
+func (i *Idempotency) release(redisKey string) {
+	if _, err := i.do("DEL", redisKey); err != nil {
+		i.Log.WithError(err).Warn("Failed to release idempotency key")
+	}
+}
+
+func (i *Idempotency) replay(c *gin.Context, redisKey, fingerprint string) {
+	raw, err := redis.Bytes(i.do("GET", redisKey))
+	if err != nil {
+		i.Log.WithError(err).Warn("Failed to read idempotent response")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"errors": "Request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(raw, &record); err != nil {
+		i.Log.WithError(err).Warn("Failed to decode idempotent response")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"errors": "Request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"errors": "Idempotency-Key was already used for a different request"})
+		return
+	}
+
+	if record.State != idempotencyDone {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"errors": "Request with this Idempotency-Key is still in progress"})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(record.StatusCode, record.ContentType, record.Body)
+	c.Abort()
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/usecase"
+	"go-todo-api/internal/util"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Permission struct {
+	Authz *usecase.AuthorizationUsecase
+}
+
+func NewPermission(authz *usecase.AuthorizationUsecase) *Permission {
+	return &Permission{
+		Authz: authz,
+	}
+}
+
+// Require aborts the request unless the authenticated user holds every
+// listed permission, either through the user_role enum or a custom role.
+func (p *Permission) Require(permissions ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := GetUser(c)
+		for _, permission := range permissions {
+			if err := p.Authz.Authorize(c, user, permission); err != nil {
+				c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
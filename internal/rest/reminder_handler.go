@@ -0,0 +1,75 @@
+package rest
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (t *TodoHandler) SetReminders(c *gin.Context) {
+	var request domain.ReminderSetRequest
+
+	todoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		t.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		t.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request.TodoID = uint(todoId)
+	request.AllUsers = isAllUsersScope(c)
+
+	responses, err := t.UseCase.SetReminders(c, middleware.GetUser(c), &request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error setting reminders")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.ReminderResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Reminders updated successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) FindReminders(c *gin.Context) {
+	todoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request := &domain.ReminderListRequest{TodoID: uint(todoId), AllUsers: isAllUsersScope(c)}
+	responses, err := t.UseCase.FindReminders(c, middleware.GetUser(c), request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error finding reminders")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.ReminderResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Reminders data retrieved successfully",
+		Data:       responses,
+	})
+}
@@ -0,0 +1,219 @@
+package rest
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type RoleUsecase interface {
+	Create(ctx context.Context, request *domain.RoleCreateRequest) (*domain.RoleResponse, error)
+	Delete(ctx context.Context, request *domain.RoleDeleteRequest) (*domain.RoleResponse, error)
+	FindAllRole(ctx context.Context) ([]*domain.RoleResponse, error)
+	AssignUser(ctx context.Context, request *domain.RoleAssignRequest) error
+	UnassignUser(ctx context.Context, request *domain.RoleAssignRequest) error
+	TwoFactorPolicy(ctx context.Context) (*domain.TwoFactorPolicyResponse, error)
+	UpdateTwoFactorPolicy(ctx context.Context, request *domain.TwoFactorPolicyRequest) (*domain.TwoFactorPolicyResponse, error)
+}
+
+type RoleHandler struct {
+	Log     *logrus.Logger
+	UseCase RoleUsecase
+}
+
+func NewRoleHandler(r *gin.Engine, u RoleUsecase, log *logrus.Logger, permission *middleware.Permission) {
+	handler := &RoleHandler{
+		UseCase: u,
+		Log:     log,
+	}
+
+	manage := permission.Require(domain.PermUserManage)
+	r.POST("v1/roles", manage, handler.Create)
+	r.GET("v1/roles", manage, handler.FindAllRole)
+	r.DELETE("v1/roles/:id", manage, handler.Delete)
+	r.PUT("v1/roles/:id/users/:user_id", manage, handler.AssignUser)
+	r.DELETE("v1/roles/:id/users/:user_id", manage, handler.UnassignUser)
+	r.GET("v1/roles/_two-factor", manage, handler.TwoFactorPolicy)
+	r.PUT("v1/roles/_two-factor", manage, handler.UpdateTwoFactorPolicy)
+}
+
+func (h *RoleHandler) Create(c *gin.Context) {
+	var (
+		role          domain.RoleCreateRequest
+		errValidation error
+		ok            bool
+	)
+
+	if err := c.ShouldBindJSON(&role); err != nil {
+		h.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, errValidation = util.IsRequestValid(&role); !ok {
+		h.Log.WithError(errValidation).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": errValidation.Error()})
+		return
+	}
+
+	response, err := h.UseCase.Create(c, &role)
+	if err != nil {
+		h.Log.WithError(err).Error("Error creating role")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.Response[*domain.RoleResponse]{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Role created successfully",
+		Data:       response,
+	})
+}
+
+func (h *RoleHandler) FindAllRole(c *gin.Context) {
+	responses, err := h.UseCase.FindAllRole(c)
+	if err != nil {
+		h.Log.WithError(err).Error("Error find roles")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.RoleResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Roles data retrieved successfully",
+		Data:       responses,
+	})
+}
+
+func (h *RoleHandler) Delete(c *gin.Context) {
+	roleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	response, err := h.UseCase.Delete(c, &domain.RoleDeleteRequest{ID: uint(roleId)})
+	if err != nil {
+		h.Log.WithError(err).Error("Error delete role")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.RoleResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Role deleted successfully",
+		Data:       response,
+	})
+}
+
+func (h *RoleHandler) AssignUser(c *gin.Context) {
+	request, ok := h.bindAssignRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.UseCase.AssignUser(c, request); err != nil {
+		h.Log.WithError(err).Error("Error assign role")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[any]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Role assigned successfully",
+	})
+}
+
+func (h *RoleHandler) UnassignUser(c *gin.Context) {
+	request, ok := h.bindAssignRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.UseCase.UnassignUser(c, request); err != nil {
+		h.Log.WithError(err).Error("Error unassign role")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[any]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Role unassigned successfully",
+	})
+}
+
+func (h *RoleHandler) TwoFactorPolicy(c *gin.Context) {
+	response, err := h.UseCase.TwoFactorPolicy(c)
+	if err != nil {
+		h.Log.WithError(err).Error("Error find two-factor policy")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.TwoFactorPolicyResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor policy retrieved successfully",
+		Data:       response,
+	})
+}
+
+func (h *RoleHandler) UpdateTwoFactorPolicy(c *gin.Context) {
+	var request domain.TwoFactorPolicyRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		h.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		h.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	response, err := h.UseCase.UpdateTwoFactorPolicy(c, &request)
+	if err != nil {
+		h.Log.WithError(err).Error("Error update two-factor policy")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.TwoFactorPolicyResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor policy updated successfully",
+		Data:       response,
+	})
+}
+
+func (h *RoleHandler) bindAssignRequest(c *gin.Context) (*domain.RoleAssignRequest, bool) {
+	roleId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return nil, false
+	}
+
+	userId, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		h.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return nil, false
+	}
+
+	return &domain.RoleAssignRequest{ID: uint(roleId), UserID: uint(userId)}, true
+}
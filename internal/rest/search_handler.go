@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type SearchUsecase interface {
+	Search(ctx context.Context, auth *entity.User, request *domain.SearchRequest) ([]*domain.SearchResponse, *domain.PaginationMeta, error)
+}
+
+type SearchHandler struct {
+	Log     *logrus.Logger
+	UseCase SearchUsecase
+}
+
+func NewSearchHandler(r *gin.Engine, s SearchUsecase, log *logrus.Logger, permission *middleware.Permission) {
+	handler := &SearchHandler{
+		UseCase: s,
+		Log:     log,
+	}
+
+	r.GET("v1/search", permission.Require(domain.PermTodoReadOwn), handler.Search)
+}
+
+func (s *SearchHandler) Search(c *gin.Context) {
+	page, size, err := parsePageQuery(c)
+	if err != nil {
+		s.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	request := &domain.SearchRequest{
+		Query:    c.Query("q"),
+		Type:     c.Query("type"),
+		Page:     page,
+		Size:     size,
+		AllUsers: isAllUsersScope(c),
+	}
+
+	if ok, errValidation := util.IsRequestValid(request); !ok {
+		s.Log.WithError(errValidation).Error("Error request query validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": errValidation.Error()})
+		return
+	}
+
+	responses, meta, err := s.UseCase.Search(c, middleware.GetUser(c), request)
+	if err != nil {
+		s.Log.WithError(err).Error("Error search")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.SearchResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Search results retrieved successfully",
+		Data:       responses,
+		Meta:       meta,
+	})
+}
@@ -0,0 +1,161 @@
+package rest
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/rest/middleware"
+	"go-todo-api/internal/util"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (t *TodoHandler) CreateSubtask(c *gin.Context) {
+	var request domain.SubtaskCreateRequest
+
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		t.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		t.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request.ParentID = uint(parentId)
+	request.AllUsers = isAllUsersScope(c)
+
+	response, err := t.UseCase.CreateSubtask(c, middleware.GetUser(c), &request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error creating subtask")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.Response[*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "Subtask created successfully",
+		Data:       response,
+	})
+}
+
+func (t *TodoHandler) FindSubtasks(c *gin.Context) {
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request := &domain.SubtaskListRequest{ParentID: uint(parentId), AllUsers: isAllUsersScope(c)}
+	responses, err := t.UseCase.FindSubtasks(c, middleware.GetUser(c), request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error finding subtasks")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Subtasks data retrieved successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) ReorderSubtasks(c *gin.Context) {
+	var request domain.SubtaskReorderRequest
+
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		t.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		t.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request.ParentID = uint(parentId)
+	request.AllUsers = isAllUsersScope(c)
+
+	responses, err := t.UseCase.ReorderSubtasks(c, middleware.GetUser(c), &request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error reordering subtasks")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Subtasks reordered successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) CompleteSubtask(c *gin.Context) {
+	t.setSubtaskCompletion(c, true)
+}
+
+func (t *TodoHandler) ReopenSubtask(c *gin.Context) {
+	t.setSubtaskCompletion(c, false)
+}
+
+func (t *TodoHandler) setSubtaskCompletion(c *gin.Context, completed bool) {
+	parentId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	subtaskUUID, err := uuid.Parse(c.Param("subtask_id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "Invalid subtask id"})
+		return
+	}
+
+	request := &domain.SubtaskCompleteRequest{
+		ParentID:    uint(parentId),
+		SubtaskUUID: subtaskUUID,
+		IsCompleted: completed,
+		AllUsers:    isAllUsersScope(c),
+	}
+
+	response, err := t.UseCase.CompleteSubtask(c, middleware.GetUser(c), request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error updating subtask")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Subtask updated successfully",
+		Data:       response,
+	})
+}
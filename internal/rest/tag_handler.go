@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"go-todo-api/domain"
+	"go-todo-api/internal/rest/middleware"
 	"go-todo-api/internal/util"
 	"net/http"
 	"strconv"
@@ -17,25 +18,28 @@ type TagUsecase interface {
 	Create(ctx context.Context, requests []*domain.TagCreateRequest) ([]*domain.TagResponse, error)
 	Update(ctx context.Context, request *domain.TagUpdateRequest) (*domain.TagResponse, error)
 	Delete(ctx context.Context, request *domain.TagDeleteRequest) (*domain.TagResponse, error)
-	FindAllTag(ctx context.Context, offset, limit int) ([]*domain.TagResponse, *domain.PaginationMeta, error)
+	FindAllTag(ctx context.Context, request *domain.TagListRequest) ([]*domain.TagResponse, *domain.PaginationMeta, error)
+	FindAllTagByCursor(ctx context.Context, request *domain.TagListRequest) ([]*domain.TagResponse, *domain.CursorMeta, error)
 	FindTagById(ctx context.Context, request *domain.TagGetDataRequest) (*domain.TagResponse, error)
 }
 type TagHandler struct {
-	Log     *logrus.Logger
-	UseCase TagUsecase
+	Log            *logrus.Logger
+	UseCase        TagUsecase
+	RequireIfMatch bool
 }
 
-func NewTagHandler(r *gin.Engine, t TagUsecase, log *logrus.Logger) {
+func NewTagHandler(r *gin.Engine, t TagUsecase, log *logrus.Logger, permission *middleware.Permission, requireIfMatch bool) {
 	handler := &TagHandler{
-		UseCase: t,
-		Log:     log,
+		UseCase:        t,
+		Log:            log,
+		RequireIfMatch: requireIfMatch,
 	}
 
-	r.POST("v1/tags", handler.Create)
-	r.PUT("v1/tags/:id", handler.Update)
-	r.GET("v1/tags", handler.FindAllTag)
-	r.GET("v1/tags/:id", handler.FindTagById)
-	r.DELETE("v1/tags/:id", handler.Delete)
+	r.POST("v1/tags", permission.Require(domain.PermTagCreate), handler.Create)
+	r.PUT("v1/tags/:id", permission.Require(domain.PermTagUpdate), handler.Update)
+	r.GET("v1/tags", permission.Require(domain.PermTagRead), handler.FindAllTag)
+	r.GET("v1/tags/:id", permission.Require(domain.PermTagRead), handler.FindTagById)
+	r.DELETE("v1/tags/:id", permission.Require(domain.PermTagDelete), handler.Delete)
 }
 
 func (t *TagHandler) Create(c *gin.Context) {
@@ -64,6 +68,11 @@ func (t *TagHandler) Create(c *gin.Context) {
 		tags = append(tags, singleTag)
 	}
 
+	if isAtomic(c) {
+		t.createAtomic(c, tags)
+		return
+	}
+
 	resultChan := make(chan *domain.TagResponse, len(tags))
 	errChan := make(chan error, len(tags))
 
@@ -133,6 +142,39 @@ func (t *TagHandler) Create(c *gin.Context) {
 	})
 }
 
+func (t *TagHandler) createAtomic(c *gin.Context, tags []domain.TagCreateRequest) {
+	var (
+		requests   = make([]*domain.TagCreateRequest, len(tags))
+		itemErrors []error
+	)
+
+	for i := range tags {
+		if ok, err := util.IsRequestValid(&tags[i]); !ok {
+			itemErrors = append(itemErrors, invalidItem(i, err))
+		}
+		requests[i] = &tags[i]
+	}
+
+	if len(itemErrors) > 0 {
+		abortWithItemErrors(c, http.StatusBadRequest, itemErrors)
+		return
+	}
+
+	responses, err := t.UseCase.Create(c, requests)
+	if err != nil {
+		t.Log.WithError(err).Error("Error creating tags atomically")
+		abortWithItemErrors(c, util.GetStatusCode(err), []error{err})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TagResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Tags created successfully",
+		Data:       responses,
+	})
+}
+
 func (t *TagHandler) Update(c *gin.Context) {
 	var (
 		tag           domain.TagUpdateRequest
@@ -159,6 +201,15 @@ func (t *TagHandler) Update(c *gin.Context) {
 		return
 	}
 
+	version, err := ifMatchVersion(c, t.RequireIfMatch && tag.Version == nil)
+	if err != nil {
+		abortWithPreconditionError(c, err)
+		return
+	}
+	if version != nil {
+		tag.Version = version
+	}
+
 	tag.ID = uint(tagId)
 	response, err := t.UseCase.Update(c, &tag)
 	if err != nil {
@@ -167,6 +218,8 @@ func (t *TagHandler) Update(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", formatETag(response.Version))
+
 	c.JSON(http.StatusOK, domain.Response[*domain.TagResponse]{
 		Status:     true,
 		StatusCode: http.StatusOK,
@@ -183,7 +236,13 @@ func (t *TagHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	response, err := t.UseCase.Delete(c, &domain.TagDeleteRequest{ID: uint(tagId)})
+	version, err := ifMatchVersion(c, t.RequireIfMatch)
+	if err != nil {
+		abortWithPreconditionError(c, err)
+		return
+	}
+
+	response, err := t.UseCase.Delete(c, &domain.TagDeleteRequest{ID: uint(tagId), Version: version})
 	if err != nil {
 		t.Log.WithError(err).Error("Error update tag")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
@@ -199,20 +258,39 @@ func (t *TagHandler) Delete(c *gin.Context) {
 }
 
 func (t *TagHandler) FindAllTag(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	page, size, err := parsePageQuery(c)
 	if err != nil {
 		t.Log.WithError(err).Warn("Invalid parsing data")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
 		return
 	}
-	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
-	if err != nil {
-		t.Log.WithError(err).Warn("Invalid parsing data")
-		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+
+	request := &domain.TagListRequest{
+		Page:       page,
+		Size:       size,
+		Cursor:     c.Query("cursor"),
+		CursorMode: isCursorMode(c),
+	}
+
+	if request.CursorMode {
+		responses, cursorMeta, err := t.UseCase.FindAllTagByCursor(c, request)
+		if err != nil {
+			t.Log.WithError(err).Error("Error find tag")
+			c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.Response[[]*domain.TagResponse]{
+			Status:     true,
+			StatusCode: http.StatusOK,
+			Message:    "Tags data retrieved successfully",
+			Data:       responses,
+			CursorMeta: cursorMeta,
+		})
 		return
 	}
 
-	responses, meta, err := t.UseCase.FindAllTag(c, page, size)
+	responses, meta, err := t.UseCase.FindAllTag(c, request)
 	if err != nil {
 		t.Log.WithError(err).Error("Error find todo")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
@@ -244,6 +322,11 @@ func (t *TagHandler) FindTagById(c *gin.Context) {
 		return
 	}
 
+	if notModified(c, formatETag(response.Version)) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, domain.Response[*domain.TagResponse]{
 		Status:     true,
 		StatusCode: http.StatusOK,
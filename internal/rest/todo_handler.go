@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
 	"go-todo-api/internal/rest/middleware"
 	"go-todo-api/internal/util"
 	"net/http"
@@ -23,30 +24,57 @@ func (e *TodoError) Error() string {
 }
 
 type TodoUsecase interface {
-	Create(ctx context.Context, requests []*domain.TodoCreateRequest) ([]*domain.TodoResponse, error)
-	Update(ctx context.Context, requests []*domain.TodoUpdateRequest) ([]*domain.TodoResponse, error)
-	Delete(ctx context.Context, request *domain.TodoDeleteRequest) ([]*domain.TodoResponse, error)
-	FindAllTodo(ctx context.Context, page, size int) ([]*domain.TodoResponse, *domain.PaginationMeta, error)
-	FindTodoByID(ctx context.Context, request *domain.TodoGetDataRequest) (*domain.TodoResponse, error)
+	Create(ctx context.Context, auth *entity.User, requests []*domain.TodoCreateRequest) ([]*domain.TodoResponse, error)
+	Update(ctx context.Context, auth *entity.User, requests []*domain.TodoUpdateRequest) ([]*domain.TodoResponse, error)
+	Patch(ctx context.Context, auth *entity.User, request *domain.TodoPatchRequest) (*domain.TodoResponse, error)
+	Delete(ctx context.Context, auth *entity.User, requests []*domain.TodoDeleteRequest) ([]*domain.TodoResponse, error)
+	FindAllTodo(ctx context.Context, auth *entity.User, request *domain.TodoListRequest) ([]*domain.TodoResponse, *domain.PaginationMeta, error)
+	FindAllTodoByCursor(ctx context.Context, auth *entity.User, request *domain.TodoListRequest) ([]*domain.TodoResponse, *domain.CursorMeta, error)
+	FindTodoByID(ctx context.Context, auth *entity.User, request *domain.TodoGetDataRequest) (*domain.TodoResponse, error)
+	CreateSubtask(ctx context.Context, auth *entity.User, request *domain.SubtaskCreateRequest) (*domain.TodoResponse, error)
+	FindSubtasks(ctx context.Context, auth *entity.User, request *domain.SubtaskListRequest) ([]*domain.TodoResponse, error)
+	ReorderSubtasks(ctx context.Context, auth *entity.User, request *domain.SubtaskReorderRequest) ([]*domain.TodoResponse, error)
+	CompleteSubtask(ctx context.Context, auth *entity.User, request *domain.SubtaskCompleteRequest) (*domain.TodoResponse, error)
+	FindOccurrences(ctx context.Context, auth *entity.User, request *domain.TodoOccurrenceRequest) ([]*domain.TodoOccurrenceResponse, error)
+	SetReminders(ctx context.Context, auth *entity.User, request *domain.ReminderSetRequest) ([]*domain.ReminderResponse, error)
+	FindReminders(ctx context.Context, auth *entity.User, request *domain.ReminderListRequest) ([]*domain.ReminderResponse, error)
 }
 
 type TodoHandler struct {
-	Log     *logrus.Logger
-	UseCase TodoUsecase
+	Log            *logrus.Logger
+	UseCase        TodoUsecase
+	RequireIfMatch bool
 }
 
-func NewTodoHandler(r *gin.Engine, t TodoUsecase, log *logrus.Logger) {
+func NewTodoHandler(r *gin.Engine, t TodoUsecase, log *logrus.Logger, permission *middleware.Permission, requireIfMatch bool) {
 	handler := &TodoHandler{
-		UseCase: t,
-		Log:     log,
+		UseCase:        t,
+		Log:            log,
+		RequireIfMatch: requireIfMatch,
 	}
 
-	requiredRole := middleware.NewRequiredRole()
-	r.POST("v1/todos", requiredRole.RoleCheck(), handler.Create)
-	r.GET("v1/todos", handler.FindAllTodo)
-	r.GET("v1/todos/:id", handler.FindTodoById)
-	r.PUT("v1/todos/:id", requiredRole.RoleCheck(), handler.Update)
-	r.DELETE("v1/todos/:id", requiredRole.RoleCheck(), handler.Delete)
+	r.POST("v1/todos", permission.Require(domain.PermTodoCreate), handler.Create)
+	r.GET("v1/todos", permission.Require(domain.PermTodoReadOwn), handler.FindAllTodo)
+	r.GET("v1/todos/_occurrences", permission.Require(domain.PermTodoReadOwn), handler.FindOccurrences)
+	r.GET("v1/todos/:id", permission.Require(domain.PermTodoReadOwn), handler.FindTodoById)
+	r.PUT("v1/todos/:id", permission.Require(domain.PermTodoUpdateOwn), handler.Update)
+	r.PATCH("v1/todos/:id", permission.Require(domain.PermTodoUpdateOwn), handler.Patch)
+	r.DELETE("v1/todos/:id", permission.Require(domain.PermTodoDeleteOwn), handler.Delete)
+
+	r.POST("v1/todos/:id/subtasks", permission.Require(domain.PermTodoCreate), handler.CreateSubtask)
+	r.GET("v1/todos/:id/subtasks", permission.Require(domain.PermTodoReadOwn), handler.FindSubtasks)
+	r.PUT("v1/todos/:id/subtasks/_order", permission.Require(domain.PermTodoUpdateOwn), handler.ReorderSubtasks)
+	r.PUT("v1/todos/:id/subtasks/:subtask_id/_complete", permission.Require(domain.PermTodoUpdateOwn), handler.CompleteSubtask)
+	r.PUT("v1/todos/:id/subtasks/:subtask_id/_reopen", permission.Require(domain.PermTodoUpdateOwn), handler.ReopenSubtask)
+
+	r.PUT("v1/todos/:id/reminders", permission.Require(domain.PermTodoUpdateOwn), handler.SetReminders)
+	r.GET("v1/todos/:id/reminders", permission.Require(domain.PermTodoReadOwn), handler.FindReminders)
+}
+
+// isAllUsersScope reports whether the caller asked for the admin-only
+// "all users" mode with ?scope=all.
+func isAllUsersScope(c *gin.Context) bool {
+	return c.Query("scope") == "all"
 }
 
 func (t *TodoHandler) Create(c *gin.Context) {
@@ -76,6 +104,11 @@ func (t *TodoHandler) Create(c *gin.Context) {
 		todos = append(todos, singleTodo)
 	}
 
+	if isAtomic(c) {
+		t.createAtomic(c, auth, todos)
+		return
+	}
+
 	resultChan := make(chan *domain.TodoResponse, len(todos))
 	errChan := make(chan error, len(todos))
 
@@ -91,8 +124,7 @@ func (t *TodoHandler) Create(c *gin.Context) {
 				return
 			}
 
-			todo.UserID = auth.ID
-			response, err := t.UseCase.Create(c, []*domain.TodoCreateRequest{&todo})
+			response, err := t.UseCase.Create(c, auth, []*domain.TodoCreateRequest{&todo})
 			if err != nil {
 				errChan <- err
 				return
@@ -156,6 +188,7 @@ func (t *TodoHandler) Update(c *gin.Context) {
 		responses   []*domain.TodoResponse
 		errors      []error
 		bulkUpdate  = c.Query("bulk") != ""
+		allUsers    = isAllUsersScope(c)
 		todoIdParam = c.Param("id")
 		todoIds     = c.QueryArray("ids")
 		wg          sync.WaitGroup
@@ -177,6 +210,24 @@ func (t *TodoHandler) Update(c *gin.Context) {
 		todos = append(todos, singleTodo)
 	}
 
+	if !bulkUpdate && len(todoIds) == 0 {
+		version, err := ifMatchVersion(c, t.RequireIfMatch && todos[0].Version == nil)
+		if err != nil {
+			abortWithPreconditionError(c, err)
+			return
+		}
+		if version != nil {
+			todos[0].Version = version
+		}
+	} else if t.RequireIfMatch {
+		for _, todo := range todos {
+			if todo.Version == nil {
+				c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"errors": "Every todo in a bulk update must include its version"})
+				return
+			}
+		}
+	}
+
 	if len(todoIds) > 0 && len(todoIds) != len(todos) {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "Mismatched number of IDs and Todo items"})
 		return
@@ -194,6 +245,11 @@ func (t *TodoHandler) Update(c *gin.Context) {
 		}
 	}
 
+	if isAtomic(c) {
+		t.updateAtomic(c, auth, todos, todoIds, allUsers)
+		return
+	}
+
 	resultChan := make(chan *domain.TodoResponse, len(todos)*len(todoIds))
 	errChan := make(chan error, len(todos)*len(todoIds))
 
@@ -217,9 +273,9 @@ func (t *TodoHandler) Update(c *gin.Context) {
 			}
 
 			todo.ID = uint(todoId)
-			todo.UserID = auth.ID
+			todo.AllUsers = allUsers
 
-			response, err := t.UseCase.Update(c, []*domain.TodoUpdateRequest{&todo})
+			response, err := t.UseCase.Update(c, auth, []*domain.TodoUpdateRequest{&todo})
 			if err != nil {
 				errChan <- err
 				return
@@ -267,6 +323,10 @@ func (t *TodoHandler) Update(c *gin.Context) {
 		return
 	}
 
+	if len(responses) == 1 {
+		c.Header("ETag", formatETag(responses[0].Version))
+	}
+
 	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
 		Status:     true,
 		StatusCode: http.StatusOK,
@@ -275,12 +335,73 @@ func (t *TodoHandler) Update(c *gin.Context) {
 	})
 }
 
+// Patch accepts application/merge-patch+json (RFC 7396) and
+// application/json-patch+json (RFC 6902) bodies.
+func (t *TodoHandler) Patch(c *gin.Context) {
+	todoId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var kind string
+	switch c.ContentType() {
+	case "application/merge-patch+json":
+		kind = domain.PatchKindMerge
+	case "application/json-patch+json":
+		kind = domain.PatchKindJSON
+	default:
+		c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"errors": "PATCH requires application/merge-patch+json or application/json-patch+json"})
+		return
+	}
+
+	patch, err := c.GetRawData()
+	if err != nil {
+		t.Log.WithError(err).Error("Error reading patch body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	version, err := ifMatchVersion(c, t.RequireIfMatch)
+	if err != nil {
+		abortWithPreconditionError(c, err)
+		return
+	}
+
+	request := &domain.TodoPatchRequest{
+		ID:       uint(todoId),
+		Version:  version,
+		AllUsers: isAllUsersScope(c),
+		Kind:     kind,
+		Patch:    patch,
+	}
+
+	response, err := t.UseCase.Patch(c, middleware.GetUser(c), request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error patching todo")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.Header("ETag", formatETag(response.Version))
+	c.JSON(http.StatusOK, domain.Response[*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Todo patched successfully",
+		Data:       response,
+	})
+}
+
 func (t *TodoHandler) Delete(c *gin.Context) {
 	var (
+		auth        = middleware.GetUser(c)
+		allUsers    = isAllUsersScope(c)
 		responses   []*domain.TodoResponse
 		errors      []error
 		todoIdParam = c.Param("id")
 		todoIds     = c.QueryArray("ids")
+		version     *uint
 		wg          sync.WaitGroup
 	)
 
@@ -289,8 +410,21 @@ func (t *TodoHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if len(todoIds) == 0 && todoIdParam != "" {
+	if len(todoIds) == 0 {
+		var err error
+		if version, err = ifMatchVersion(c, t.RequireIfMatch); err != nil {
+			abortWithPreconditionError(c, err)
+			return
+		}
 		todoIds = append(todoIds, todoIdParam)
+	} else if t.RequireIfMatch {
+		c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{"errors": "Bulk delete is not available while If-Match is required"})
+		return
+	}
+
+	if isAtomic(c) {
+		t.deleteAtomic(c, auth, todoIds, version, allUsers)
+		return
 	}
 
 	resultChan := make(chan *domain.TodoResponse, len(todoIds))
@@ -308,8 +442,8 @@ func (t *TodoHandler) Delete(c *gin.Context) {
 				return
 			}
 
-			todo := &domain.TodoDeleteRequest{ID: uint(todoId)}
-			response, err := t.UseCase.Delete(c, todo)
+			todo := &domain.TodoDeleteRequest{ID: uint(todoId), Version: version, AllUsers: allUsers}
+			response, err := t.UseCase.Delete(c, auth, []*domain.TodoDeleteRequest{todo})
 			if err != nil {
 				errChan <- err
 				return
@@ -364,21 +498,148 @@ func (t *TodoHandler) Delete(c *gin.Context) {
 	})
 }
 
-func (t *TodoHandler) FindAllTodo(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+func (t *TodoHandler) createAtomic(c *gin.Context, auth *entity.User, todos []domain.TodoCreateRequest) {
+	var (
+		requests   = make([]*domain.TodoCreateRequest, len(todos))
+		itemErrors []error
+	)
+
+	for i := range todos {
+		if ok, err := util.IsRequestValid(&todos[i]); !ok {
+			itemErrors = append(itemErrors, invalidItem(i, err))
+		}
+		requests[i] = &todos[i]
+	}
+
+	if len(itemErrors) > 0 {
+		abortWithItemErrors(c, http.StatusBadRequest, itemErrors)
+		return
+	}
+
+	responses, err := t.UseCase.Create(c, auth, requests)
 	if err != nil {
-		t.Log.WithError(err).Warn("Invalid parsing data")
-		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		t.Log.WithError(err).Error("Error creating todos atomically")
+		abortWithItemErrors(c, util.GetStatusCode(err), []error{err})
 		return
 	}
-	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Todos created successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) updateAtomic(c *gin.Context, auth *entity.User, todos []domain.TodoUpdateRequest, todoIds []string, allUsers bool) {
+	var (
+		requests   = make([]*domain.TodoUpdateRequest, len(todos))
+		itemErrors []error
+	)
+
+	for i := range todos {
+		if ok, err := util.IsRequestValid(&todos[i]); !ok {
+			itemErrors = append(itemErrors, invalidItem(i, err))
+			continue
+		}
+
+		todoId, err := strconv.ParseUint(todoIds[i], 10, 64)
+		if err != nil {
+			itemErrors = append(itemErrors, invalidItem(i, fmt.Errorf("invalid todo ID: %s", todoIds[i])))
+			continue
+		}
+
+		todos[i].ID = uint(todoId)
+		todos[i].AllUsers = allUsers
+		requests[i] = &todos[i]
+	}
+
+	if len(itemErrors) > 0 {
+		abortWithItemErrors(c, http.StatusBadRequest, itemErrors)
+		return
+	}
+
+	responses, err := t.UseCase.Update(c, auth, requests)
+	if err != nil {
+		t.Log.WithError(err).Error("Error updating todos atomically")
+		abortWithItemErrors(c, util.GetStatusCode(err), []error{err})
+		return
+	}
+
+	if len(responses) == 1 {
+		c.Header("ETag", formatETag(responses[0].Version))
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Todos updated successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) deleteAtomic(c *gin.Context, auth *entity.User, todoIds []string, version *uint, allUsers bool) {
+	var (
+		requests   = make([]*domain.TodoDeleteRequest, len(todoIds))
+		itemErrors []error
+	)
+
+	for i, todoIdStr := range todoIds {
+		todoId, err := strconv.ParseUint(todoIdStr, 10, 64)
+		if err != nil {
+			itemErrors = append(itemErrors, invalidItem(i, fmt.Errorf("invalid todo ID: %s", todoIdStr)))
+			continue
+		}
+		requests[i] = &domain.TodoDeleteRequest{ID: uint(todoId), Version: version, AllUsers: allUsers}
+	}
+
+	if len(itemErrors) > 0 {
+		abortWithItemErrors(c, http.StatusBadRequest, itemErrors)
+		return
+	}
+
+	responses, err := t.UseCase.Delete(c, auth, requests)
+	if err != nil {
+		t.Log.WithError(err).Error("Error deleting todos atomically")
+		abortWithItemErrors(c, util.GetStatusCode(err), []error{err})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Todos deleted successfully",
+		Data:       responses,
+	})
+}
+
+func (t *TodoHandler) FindAllTodo(c *gin.Context) {
+	request, err := parseTodoListRequest(c)
 	if err != nil {
 		t.Log.WithError(err).Warn("Invalid parsing data")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
 		return
 	}
 
-	responses, meta, err := t.UseCase.FindAllTodo(c, page, size)
+	if request.CursorMode {
+		responses, cursorMeta, err := t.UseCase.FindAllTodoByCursor(c, middleware.GetUser(c), request)
+		if err != nil {
+			t.Log.WithError(err).Error("Error find todo")
+			c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, domain.Response[[]*domain.TodoResponse]{
+			Status:     true,
+			StatusCode: http.StatusOK,
+			Message:    "Todos data retrieved successfully",
+			Data:       responses,
+			CursorMeta: cursorMeta,
+		})
+		return
+	}
+
+	responses, meta, err := t.UseCase.FindAllTodo(c, middleware.GetUser(c), request)
 	if err != nil {
 		t.Log.WithError(err).Error("Error find todo")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
@@ -402,14 +663,19 @@ func (t *TodoHandler) FindTodoById(c *gin.Context) {
 		return
 	}
 
-	todo := &domain.TodoGetDataRequest{ID: uint(todoId)}
-	response, err := t.UseCase.FindTodoByID(c, todo)
+	todo := &domain.TodoGetDataRequest{ID: uint(todoId), AllUsers: isAllUsersScope(c)}
+	response, err := t.UseCase.FindTodoByID(c, middleware.GetUser(c), todo)
 	if err != nil {
 		t.Log.WithError(err).Error("Error finding todo")
 		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
 		return
 	}
 
+	if notModified(c, formatETag(response.Version)) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+
 	c.JSON(http.StatusOK, domain.Response[*domain.TodoResponse]{
 		Status:     true,
 		StatusCode: http.StatusOK,
@@ -417,3 +683,26 @@ func (t *TodoHandler) FindTodoById(c *gin.Context) {
 		Data:       response,
 	})
 }
+
+func (t *TodoHandler) FindOccurrences(c *gin.Context) {
+	request, err := parseOccurrenceRequest(c)
+	if err != nil {
+		t.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	responses, err := t.UseCase.FindOccurrences(c, middleware.GetUser(c), request)
+	if err != nil {
+		t.Log.WithError(err).Error("Error finding occurrences")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.TodoOccurrenceResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Todo occurrences retrieved successfully",
+		Data:       responses,
+	})
+}
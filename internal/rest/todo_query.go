@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"fmt"
+	"go-todo-api/domain"
+	"go-todo-api/internal/util"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxPageSize = 100
+
+func badQuery(format string, args ...any) error {
+	return util.NewCustomError(int(util.ErrBadRequestCode), fmt.Sprintf(format, args...))
+}
+
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, badQuery("invalid %s: expected RFC3339 timestamp or YYYY-MM-DD date", key)
+}
+
+func parsePageQuery(c *gin.Context) (int, int, error) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		return 0, 0, badQuery("invalid page: must be a positive integer")
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "10"))
+	if err != nil || size < 1 || size > maxPageSize {
+		return 0, 0, badQuery("invalid size: must be between 1 and %d", maxPageSize)
+	}
+
+	return page, size, nil
+}
+
+// isCursorMode reports whether the listing should use keyset pagination,
+// either by passing a cursor from a previous page or ?pagination=cursor.
+func isCursorMode(c *gin.Context) bool {
+	return c.Query("cursor") != "" || c.Query("pagination") == "cursor"
+}
+
+// parseTodoSort reads a comma separated list of fields, each optionally
+// prefixed with "-" for descending order, e.g. sort=due_time,-created_at.
+func parseTodoSort(value string) ([]domain.SortKey, error) {
+	var sorts []domain.SortKey
+	if value == "" {
+		return sorts, nil
+	}
+
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		sort := domain.SortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if !domain.TodoSortableFields[sort.Field] {
+			return nil, badQuery("invalid sort field %q", sort.Field)
+		}
+		sorts = append(sorts, sort)
+	}
+
+	return sorts, nil
+}
+
+func parseTodoFilter(c *gin.Context) (domain.TodoFilter, error) {
+	var (
+		filter domain.TodoFilter
+		err    error
+	)
+
+	if value := c.Query("is_completed"); value != "" {
+		isCompleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, badQuery("invalid is_completed: must be true or false")
+		}
+		filter.IsCompleted = &isCompleted
+	}
+
+	switch c.Query("due") {
+	case "":
+	case "overdue":
+		filter.Overdue = true
+	case "today":
+		filter.DueToday = true
+	default:
+		return filter, badQuery("invalid due: must be overdue or today")
+	}
+
+	timeQueries := map[string]**time.Time{
+		"due_after":      &filter.DueAfter,
+		"due_before":     &filter.DueBefore,
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+		"updated_after":  &filter.UpdatedAfter,
+		"updated_before": &filter.UpdatedBefore,
+	}
+	for key, target := range timeQueries {
+		if *target, err = parseTimeQuery(c, key); err != nil {
+			return filter, err
+		}
+	}
+
+	if value := c.Query("tags"); value != "" {
+		for _, raw := range strings.Split(value, ",") {
+			tagUUID, err := uuid.Parse(strings.TrimSpace(raw))
+			if err != nil {
+				return filter, badQuery("invalid tag uuid %q", raw)
+			}
+			// tag_match=all counts the distinct tags a todo matches, so a
+			// repeated tag would never match.
+			if !slices.Contains(filter.TagUUIDs, tagUUID) {
+				filter.TagUUIDs = append(filter.TagUUIDs, tagUUID)
+			}
+		}
+	}
+
+	switch c.DefaultQuery("tag_match", "any") {
+	case "any":
+	case "all":
+		filter.TagMatchAll = true
+	default:
+		return filter, badQuery("invalid tag_match: must be any or all")
+	}
+
+	filter.Title = c.Query("title")
+	filter.Description = c.Query("description")
+
+	return filter, nil
+}
+
+func parseTodoListRequest(c *gin.Context) (*domain.TodoListRequest, error) {
+	page, size, err := parsePageQuery(c)
+	if err != nil {
+		return nil, err
+	}
+
+	filter, err := parseTodoFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	sorts, err := parseTodoSort(c.Query("sort"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.TodoListRequest{
+		Page:       page,
+		Size:       size,
+		Cursor:     c.Query("cursor"),
+		CursorMode: isCursorMode(c),
+		AllUsers:   isAllUsersScope(c),
+		Filter:     filter,
+		Sort:       sorts,
+	}, nil
+}
+
+const (
+	defaultOccurrenceWindow = 30 * 24 * time.Hour
+	maxOccurrenceWindow     = 366 * 24 * time.Hour
+)
+
+// parseOccurrenceRequest reads the from/to window, defaulting to the next
+// 30 days.
+func parseOccurrenceRequest(c *gin.Context) (*domain.TodoOccurrenceRequest, error) {
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		return nil, err
+	}
+
+	request := &domain.TodoOccurrenceRequest{From: time.Now(), AllUsers: isAllUsersScope(c)}
+	if from != nil {
+		request.From = *from
+	}
+	request.To = request.From.Add(defaultOccurrenceWindow)
+	if to != nil {
+		request.To = *to
+	}
+
+	if !request.To.After(request.From) {
+		return nil, badQuery("invalid window: to must be after from")
+	}
+	if request.To.Sub(request.From) > maxOccurrenceWindow {
+		return nil, badQuery("invalid window: at most 366 days")
+	}
+
+	return request, nil
+}
@@ -6,17 +6,37 @@ import (
 	"go-todo-api/internal/rest/middleware"
 	"go-todo-api/internal/util"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
 type UserUseCase interface {
 	Create(ctx context.Context, user *domain.RegisterUserRequest) (*domain.UserResponse, error)
 	Login(ctx context.Context, user *domain.LoginUserRequest) (*domain.UserResponse, error)
+	Refresh(ctx context.Context, request *domain.RefreshTokenRequest) (*domain.UserResponse, error)
+	ForgotPassword(ctx context.Context, request *domain.ForgotPasswordRequest) error
+	ResetPassword(ctx context.Context, request *domain.ResetPasswordRequest) error
+	VerifyEmail(ctx context.Context, request *domain.VerifyEmailRequest) (*domain.UserResponse, error)
+	StartSSO(ctx context.Context) (*domain.SSOStartResponse, error)
+	CompleteSSO(ctx context.Context, request *domain.SSOCallbackRequest) (*domain.UserResponse, error)
+	ResendVerification(ctx context.Context, request *domain.ResendVerificationRequest) error
+	EnrollTwoFactor(ctx context.Context, request *domain.TwoFactorEnrollRequest) (*domain.TwoFactorEnrollResponse, error)
+	ConfirmTwoFactor(ctx context.Context, request *domain.TwoFactorCodeRequest) (*domain.RecoveryCodesResponse, error)
+	RegenerateRecoveryCodes(ctx context.Context, request *domain.TwoFactorCodeRequest) (*domain.RecoveryCodesResponse, error)
+	DisableTwoFactor(ctx context.Context, request *domain.TwoFactorCodeRequest) error
+	CreateAPIKey(ctx context.Context, request *domain.APIKeyCreateRequest) (*domain.APIKeyResponse, error)
+	APIKeys(ctx context.Context, request *domain.APIKeyListRequest) ([]*domain.APIKeyResponse, error)
+	RevokeAPIKey(ctx context.Context, request *domain.APIKeyRevokeRequest) error
 	Logout(ctx context.Context, request *domain.LogoutUserRequest) (bool, error)
+	Sessions(ctx context.Context, request *domain.SessionListRequest) ([]*domain.SessionResponse, error)
+	RevokeSession(ctx context.Context, request *domain.SessionRevokeRequest) error
 	Current(ctx context.Context, request *domain.CurrentUserRequest) (*domain.UserResponse, error)
 	Update(ctx context.Context, request *domain.UserUpdateRequest) (*domain.UserResponse, error)
+	Preferences(ctx context.Context, request *domain.CurrentUserRequest) (*domain.UserPreferenceResponse, error)
+	UpdatePreferences(ctx context.Context, request *domain.UserPreferenceUpdateRequest) (*domain.UserPreferenceResponse, error)
 }
 
 type UserHandler struct {
@@ -24,7 +44,7 @@ type UserHandler struct {
 	UseCase UserUseCase
 }
 
-func NewUserHandler(r *gin.Engine, u UserUseCase, log *logrus.Logger, authMiddleware gin.HandlerFunc) {
+func NewUserHandler(r *gin.Engine, u UserUseCase, log *logrus.Logger, authMiddleware, idempotencyMiddleware gin.HandlerFunc) {
 	handler := &UserHandler{
 		UseCase: u,
 		Log:     log,
@@ -32,10 +52,35 @@ func NewUserHandler(r *gin.Engine, u UserUseCase, log *logrus.Logger, authMiddle
 
 	r.POST("v1/users", handler.Register)
 	r.POST("v1/users/_login", handler.Login)
-	r.Use(authMiddleware)
-	r.DELETE("v1/users", handler.Logout)
-	r.GET("v1/users/_current", handler.Current)
-	r.PUT("v1/users/_current", handler.Update)
+	r.POST("v1/users/_refresh", handler.Refresh)
+	r.POST("v1/users/_forgot-password", handler.ForgotPassword)
+	r.POST("v1/users/_reset-password", handler.ResetPassword)
+	r.POST("v1/users/_verify-email", handler.VerifyEmail)
+	r.GET("v1/users/_sso/login", handler.SSOLogin)
+	r.GET("v1/users/_sso/callback", handler.SSOCallback)
+	r.Use(authMiddleware, idempotencyMiddleware)
+
+	// API keys may read the account with the user:read scope.
+	read := middleware.RequireSessionOrScope(domain.PermUserRead)
+	r.GET("v1/users/_current", read, handler.Current)
+	r.GET("v1/users/_current/preferences", read, handler.Preferences)
+
+	// The rest of the account is out of reach of API keys, so a key cannot
+	// be used to issue itself broader ones or take over the account.
+	account := middleware.RequireSession()
+	r.DELETE("v1/users", account, handler.Logout)
+	r.GET("v1/users/sessions", account, handler.Sessions)
+	r.DELETE("v1/users/sessions/:id", account, handler.RevokeSession)
+	r.PUT("v1/users/_current", account, handler.Update)
+	r.POST("v1/users/_current/verification", account, handler.ResendVerification)
+	r.POST("v1/users/_current/two_factor", account, handler.EnrollTwoFactor)
+	r.POST("v1/users/_current/two_factor/_confirm", account, handler.ConfirmTwoFactor)
+	r.POST("v1/users/_current/two_factor/recovery_codes", account, handler.RegenerateRecoveryCodes)
+	r.DELETE("v1/users/_current/two_factor", account, handler.DisableTwoFactor)
+	r.PUT("v1/users/_current/preferences", account, handler.UpdatePreferences)
+	r.GET("v1/users/_current/api-keys", account, handler.APIKeys)
+	r.POST("v1/users/_current/api-keys", account, handler.CreateAPIKey)
+	r.DELETE("v1/users/_current/api-keys/:id", account, handler.RevokeAPIKey)
 }
 
 func (u *UserHandler) Register(c *gin.Context) {
@@ -91,6 +136,9 @@ func (u *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	user.UserAgent = c.Request.UserAgent()
+	user.IPAddress = c.ClientIP()
+
 	response, err := u.UseCase.Login(c, &user)
 	if err != nil {
 		u.Log.WithError(err).Error("Error login User")
@@ -98,14 +146,330 @@ func (u *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
+	message := "User login successfully"
+	if response.TwoFactorRequired {
+		message = "Two-factor code required to complete login"
+	}
+
 	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
 		Status:     true,
 		StatusCode: http.StatusOK,
-		Message:    "User login successfully",
+		Message:    message,
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) Refresh(c *gin.Context) {
+	var request domain.RefreshTokenRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request.UserAgent = c.Request.UserAgent()
+	request.IPAddress = c.ClientIP()
+
+	response, err := u.UseCase.Refresh(c, &request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error refreshing token")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Token refreshed successfully",
+		Data:       response,
+	})
+}
+
+// ForgotPassword answers the same whether or not the email is registered.
+func (u *UserHandler) ForgotPassword(c *gin.Context) {
+	var request domain.ForgotPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := u.UseCase.ForgotPassword(c, &request); err != nil {
+		u.Log.WithError(err).Error("Error requesting password reset")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "If the email is registered, a password reset link has been sent to it",
+	})
+}
+
+func (u *UserHandler) ResetPassword(c *gin.Context) {
+	var request domain.ResetPasswordRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := u.UseCase.ResetPassword(c, &request); err != nil {
+		u.Log.WithError(err).Error("Error resetting password")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Password reset successfully, please log in again",
+	})
+}
+
+func (u *UserHandler) VerifyEmail(c *gin.Context) {
+	var request domain.VerifyEmailRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	response, err := u.UseCase.VerifyEmail(c, &request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error verifying email")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Email verified successfully",
+		Data:       response,
+	})
+}
+
+// ssoStateCookie holds the hash of the single sign-on state, which
+// CompleteSSO requires, so a sign-in cannot be completed into someone
+// else's browser. It lasts as long as the state does.
+const (
+	ssoStateCookie = "sso_state"
+	ssoStateMaxAge = 10 * time.Minute
+)
+
+// SSOLogin sends the browser to the identity provider to sign in.
+func (u *UserHandler) SSOLogin(c *gin.Context) {
+	response, err := u.UseCase.StartSSO(c)
+	if err != nil {
+		u.Log.WithError(err).Error("Error starting single sign-on")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	// Lax, since the provider sends the browser back with a top-level GET.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, response.StateHash, int(ssoStateMaxAge.Seconds()), "/v1/users/_sso", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, response.URL)
+}
+
+// SSOCallback is where the identity provider sends the browser back to. It
+// answers like Login, with tokens or a two-factor challenge.
+func (u *UserHandler) SSOCallback(c *gin.Context) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, "", -1, "/v1/users/_sso", "", c.Request.TLS != nil, true)
+
+	if providerError := c.Query("error"); providerError != "" {
+		u.Log.Warnf("Identity provider refused sign-in: %s %s", providerError, c.Query("error_description"))
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errors": "Sign-in was refused by the identity provider: " + providerError})
+		return
+	}
+
+	stateHash, _ := c.Cookie(ssoStateCookie)
+	request := domain.SSOCallbackRequest{
+		Code:      c.Query("code"),
+		State:     c.Query("state"),
+		StateHash: stateHash,
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request query validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	response, err := u.UseCase.CompleteSSO(c, &request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error completing single sign-on")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	message := "User login successfully"
+	if response.TwoFactorRequired {
+		message = "Two-factor code required to complete login"
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    message,
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) ResendVerification(c *gin.Context) {
+	request := &domain.ResendVerificationRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+	}
+
+	if err := u.UseCase.ResendVerification(c, request); err != nil {
+		u.Log.WithError(err).Error("Error resending verification email")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusAccepted,
+		Message:    "Verification email sent",
+	})
+}
+
+func (u *UserHandler) EnrollTwoFactor(c *gin.Context) {
+	request := &domain.TwoFactorEnrollRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+	}
+
+	response, err := u.UseCase.EnrollTwoFactor(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error enrolling two-factor authentication")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.TwoFactorEnrollResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Add the secret to your authenticator app, then confirm with a code",
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) ConfirmTwoFactor(c *gin.Context) {
+	request, ok := u.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	response, err := u.UseCase.ConfirmTwoFactor(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error confirming two-factor authentication")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.RecoveryCodesResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor authentication enabled, store the recovery codes safely",
 		Data:       response,
 	})
 }
 
+func (u *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	request, ok := u.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	response, err := u.UseCase.RegenerateRecoveryCodes(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error regenerating recovery codes")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.RecoveryCodesResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Recovery codes regenerated, the previous ones no longer work",
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) DisableTwoFactor(c *gin.Context) {
+	request, ok := u.bindTwoFactorCode(c)
+	if !ok {
+		return
+	}
+
+	if err := u.UseCase.DisableTwoFactor(c, request); err != nil {
+		u.Log.WithError(err).Error("Error disabling two-factor authentication")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Two-factor authentication disabled",
+	})
+}
+
+func (u *UserHandler) bindTwoFactorCode(c *gin.Context) (*domain.TwoFactorCodeRequest, bool) {
+	var request domain.TwoFactorCodeRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return nil, false
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return nil, false
+	}
+
+	request.ID = middleware.GetUser(c).ID
+	return &request, true
+}
+
 func (u *UserHandler) Logout(c *gin.Context) {
 	auth := middleware.GetUser(c)
 
@@ -113,6 +477,7 @@ func (u *UserHandler) Logout(c *gin.Context) {
 		GetUserId: domain.GetUserId{
 			ID: auth.ID,
 		},
+		SessionID: middleware.GetSession(c).UUID,
 	}
 
 	_, err := u.UseCase.Logout(c, request)
@@ -174,6 +539,7 @@ func (u *UserHandler) Update(c *gin.Context) {
 
 	auth := middleware.GetUser(c)
 	user.ID = auth.ID
+	user.SessionID = middleware.GetSession(c).UUID
 
 	response, err := u.UseCase.Update(c, &user)
 	if err != nil {
@@ -189,3 +555,191 @@ func (u *UserHandler) Update(c *gin.Context) {
 		Data:       response,
 	})
 }
+
+func (u *UserHandler) Preferences(c *gin.Context) {
+	auth := middleware.GetUser(c)
+
+	request := &domain.CurrentUserRequest{
+		GetUserId: domain.GetUserId{
+			ID: auth.ID,
+		},
+	}
+
+	response, err := u.UseCase.Preferences(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error get user preferences")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserPreferenceResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User preferences retrieved successfully",
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) UpdatePreferences(c *gin.Context) {
+	var request domain.UserPreferenceUpdateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	request.UserID = middleware.GetUser(c).ID
+
+	response, err := u.UseCase.UpdatePreferences(c, &request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error update user preferences")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.UserPreferenceResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "User preferences updated successfully",
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) Sessions(c *gin.Context) {
+	request := &domain.SessionListRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+		SessionID: middleware.GetSession(c).UUID,
+	}
+
+	responses, err := u.UseCase.Sessions(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error find sessions")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.SessionResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Sessions retrieved successfully",
+		Data:       responses,
+	})
+}
+
+func (u *UserHandler) RevokeSession(c *gin.Context) {
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		u.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "invalid session id"})
+		return
+	}
+
+	request := &domain.SessionRevokeRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+		UUID: sessionID,
+	}
+
+	if err := u.UseCase.RevokeSession(c, request); err != nil {
+		u.Log.WithError(err).Error("Error revoke session")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[*domain.SessionResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "Session revoked successfully",
+	})
+}
+
+func (u *UserHandler) CreateAPIKey(c *gin.Context) {
+	var request domain.APIKeyCreateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		u.Log.WithError(err).Error("Error parsing request body")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if ok, err := util.IsRequestValid(&request); !ok {
+		u.Log.WithError(err).Error("Error request body validation")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": err.Error()})
+		return
+	}
+	request.ID = middleware.GetUser(c).ID
+
+	response, err := u.UseCase.CreateAPIKey(c, &request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error creating API key")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, domain.Response[*domain.APIKeyResponse]{
+		Status:     true,
+		StatusCode: http.StatusCreated,
+		Message:    "API key created, store the key now as it is not shown again",
+		Data:       response,
+	})
+}
+
+func (u *UserHandler) APIKeys(c *gin.Context) {
+	request := &domain.APIKeyListRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+	}
+
+	responses, err := u.UseCase.APIKeys(c, request)
+	if err != nil {
+		u.Log.WithError(err).Error("Error find API keys")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[[]*domain.APIKeyResponse]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "API keys retrieved successfully",
+		Data:       responses,
+	})
+}
+
+func (u *UserHandler) RevokeAPIKey(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		u.Log.WithError(err).Warn("Invalid parsing data")
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errors": "invalid API key id"})
+		return
+	}
+
+	request := &domain.APIKeyRevokeRequest{
+		GetUserId: domain.GetUserId{
+			ID: middleware.GetUser(c).ID,
+		},
+		UUID: keyID,
+	}
+
+	if err := u.UseCase.RevokeAPIKey(c, request); err != nil {
+		u.Log.WithError(err).Error("Error revoking API key")
+		c.AbortWithStatusJSON(util.GetStatusCode(err), gin.H{"errors": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, domain.Response[any]{
+		Status:     true,
+		StatusCode: http.StatusOK,
+		Message:    "API key revoked successfully",
+	})
+}
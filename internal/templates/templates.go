@@ -0,0 +1,164 @@
+// Package templates renders outgoing emails from templates embedded in the
+// binary. Every kind has, per locale, an HTML body in emails/<locale>/<kind>.html
+// defining "content", and a text file in emails/<locale>/<kind>.txt defining
+// "subject" and "text".
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+type Kind string
+
+const (
+	KindTodoCreated Kind = "todo_created"
+	KindTodoUpdated Kind = "todo_updated"
+	KindTodoDeleted Kind = "todo_deleted"
+	KindReminder    Kind = "reminder"
+	KindOverdue     Kind = "overdue"
+	KindDigest      Kind = "digest"
+
+	KindPasswordReset     Kind = "password_reset"
+	KindEmailVerification Kind = "email_verification"
+)
+
+var Kinds = []Kind{
+	KindTodoCreated,
+	KindTodoUpdated,
+	KindTodoDeleted,
+	KindReminder,
+	KindOverdue,
+	KindDigest,
+	KindPasswordReset,
+	KindEmailVerification,
+}
+
+const DefaultLocale = "en"
+
+var Locales = []string{"en", "id"}
+
+const timeLayout = "02 Jan 2006 15:04 MST"
+
+//go:embed emails
+var files embed.FS
+
+// Recipient is who an email is rendered for. Templates reach it as
+// .Recipient and format times with .Recipient.Time.
+type Recipient struct {
+	Name     string
+	Email    string
+	Locale   string
+	Location *time.Location
+}
+
+func (r Recipient) Time(t time.Time) string {
+	return t.In(r.location()).Format(timeLayout)
+}
+
+func (r Recipient) Timezone() string {
+	return r.location().String()
+}
+
+func (r Recipient) location() *time.Location {
+	if r.Location == nil {
+		return time.UTC
+	}
+	return r.Location
+}
+
+// Message is the value every template is executed with.
+type Message struct {
+	Recipient Recipient
+	Data      any
+}
+
+type Email struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+// section bundles a titled list of items with the message it belongs to, so
+// shared list templates can still reach the recipient.
+type section struct {
+	Title   string
+	Items   any
+	Message Message
+}
+
+func newSection(title string, items any, message Message) section {
+	return section{Title: title, Items: items, Message: message}
+}
+
+type localized struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var registry = parse()
+
+// parse loads every kind for every locale, so a missing or broken template
+// fails at startup rather than when the email is sent.
+func parse() map[string]map[Kind]localized {
+	registry := make(map[string]map[Kind]localized, len(Locales))
+	for _, locale := range Locales {
+		registry[locale] = make(map[Kind]localized, len(Kinds))
+		for _, kind := range Kinds {
+			html := htmltemplate.Must(htmltemplate.New(string(kind)).
+				Funcs(htmltemplate.FuncMap{"section": newSection}).
+				ParseFS(files, "emails/layout.html", fmt.Sprintf("emails/%s/%s.html", locale, kind)))
+			text := texttemplate.Must(texttemplate.New(string(kind)).
+				Funcs(texttemplate.FuncMap{"section": newSection}).
+				ParseFS(files, fmt.Sprintf("emails/%s/%s.txt", locale, kind)))
+			registry[locale][kind] = localized{html: html, text: text}
+		}
+	}
+	return registry
+}
+
+func IsLocale(locale string) bool {
+	_, ok := registry[locale]
+	return ok
+}
+
+func IsKind(kind Kind) bool {
+	_, ok := registry[DefaultLocale][kind]
+	return ok
+}
+
+// Render renders kind for recipient, falling back to DefaultLocale when the
+// recipient's locale is unknown.
+func Render(kind Kind, recipient Recipient, data any) (*Email, error) {
+	if !IsLocale(recipient.Locale) {
+		recipient.Locale = DefaultLocale
+	}
+	tmpl, ok := registry[recipient.Locale][kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", kind)
+	}
+
+	message := Message{Recipient: recipient, Data: data}
+	var subject, html, text bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", message); err != nil {
+		return nil, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", message); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", message); err != nil {
+		return nil, err
+	}
+
+	return &Email{
+		// Titles can hold line breaks, which must not reach the header.
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// BuiltinRolePermissions maps the values of the user_role enum to the
+// permissions they grant. Custom roles from the roles table add on top.
+var BuiltinRolePermissions = map[string][]domain.Permission{
+	entity.RoleAdmin: domain.AllPermissions,
+	entity.RoleUser: {
+		domain.PermTodoCreate,
+		domain.PermTodoReadOwn,
+		domain.PermTodoUpdateOwn,
+		domain.PermTodoDeleteOwn,
+		domain.PermTagCreate,
+		domain.PermTagRead,
+		domain.PermUserRead,
+	},
+}
+
+type PermissionRepository interface {
+	FindPermissionsByRoleID(ctx context.Context, roleID uint) ([]string, error)
+}
+
+type AuthorizationUsecase struct {
+	Log            *logrus.Logger
+	PermissionRepo PermissionRepository
+	TwoFactorRepo  TwoFactorPolicyRepository
+}
+
+func NewAuthorizationUsecase(p PermissionRepository, twoFactorRepo TwoFactorPolicyRepository, logger *logrus.Logger) *AuthorizationUsecase {
+	return &AuthorizationUsecase{
+		Log:            logger,
+		PermissionRepo: p,
+		TwoFactorRepo:  twoFactorRepo,
+	}
+}
+
+// Can reports whether user holds permission. A user authenticated with an
+// API key only holds the permissions that are also among its scopes.
+func (a *AuthorizationUsecase) Can(ctx context.Context, user *entity.User, permission domain.Permission) (bool, error) {
+	if user == nil {
+		return false, nil
+	}
+	if user.Scopes != nil && !slices.Contains(user.Scopes, string(permission)) {
+		return false, nil
+	}
+
+	for _, p := range BuiltinRolePermissions[user.Role] {
+		if p == permission {
+			return true, nil
+		}
+	}
+
+	if user.RoleID == nil {
+		return false, nil
+	}
+
+	permissions, err := a.PermissionRepo.FindPermissionsByRoleID(ctx, *user.RoleID)
+	if err != nil {
+		a.Log.WithError(err).Error("Failed to find role permissions")
+		return false, err
+	}
+
+	for _, p := range permissions {
+		if p == string(permission) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Authorize refuses users without permission, and users who have yet to
+// enroll in two-factor authentication their role requires.
+func (a *AuthorizationUsecase) Authorize(ctx context.Context, user *entity.User, permission domain.Permission) error {
+	if user == nil {
+		return util.NewCustomError(int(util.ErrUnauthorizedCode), "Unauthorized")
+	}
+
+	if user.TOTPEnabledAt == nil {
+		required, err := a.TwoFactorRepo.RequiresTwoFactor(ctx, user.Role)
+		if err != nil {
+			a.Log.WithError(err).Error("Failed to find two-factor policy")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if required {
+			a.Log.Warnf("User %d has not enrolled in required two-factor authentication", user.ID)
+			return util.NewCustomError(int(util.ErrForbiddenCode), "Two-factor authentication is required for your role, enroll at v1/users/_current/two_factor")
+		}
+	}
+
+	allowed, err := a.Can(ctx, user, permission)
+	if err != nil {
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	if !allowed {
+		a.Log.Warnf("User %d is missing permission %s", user.ID, permission)
+		return util.NewCustomError(int(util.ErrForbiddenCode), "Insufficient permissions")
+	}
+
+	return nil
+}
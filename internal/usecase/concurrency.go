@@ -0,0 +1,16 @@
+package usecase
+
+import "go-todo-api/internal/util"
+
+// checkVersion rejects a write when the caller sent the version it last saw
+// and the stored row has moved on since.
+func checkVersion(expected *uint, current uint, resource string) error {
+	if expected != nil && *expected != current {
+		return modifiedError(resource)
+	}
+	return nil
+}
+
+func modifiedError(resource string) error {
+	return util.NewCustomError(int(util.ErrPreconditionFailedCode), resource+" has been modified by another request")
+}
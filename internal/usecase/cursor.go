@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"go-todo-api/domain"
+	"go-todo-api/internal/util"
+	"strings"
+)
+
+func sortSignature(sorts []domain.SortKey) string {
+	fields := make([]string, 0, len(sorts))
+	for _, sort := range sorts {
+		if sort.Desc {
+			fields = append(fields, "-"+sort.Field)
+		} else {
+			fields = append(fields, sort.Field)
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// decodeCursorPage validates a client cursor against the sort it is used
+// with, an empty cursor starts at the first page.
+func decodeCursorPage(codec *util.CursorCodec, cursor string, sorts []domain.SortKey, size int) (*domain.CursorPage, error) {
+	page := &domain.CursorPage{Limit: size}
+	if cursor == "" {
+		return page, nil
+	}
+
+	token, err := codec.Decode(cursor)
+	if err != nil || token.Sort != sortSignature(sorts) {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Invalid cursor")
+	}
+
+	page.Values = token.Values
+	page.Backward = token.Backward
+	return page, nil
+}
+
+// buildCursorMeta issues the tokens pointing before the first and after the
+// last item of a page read with decodeCursorPage.
+func buildCursorMeta[T any](codec *util.CursorCodec, sorts []domain.SortKey, page *domain.CursorPage, items []T, hasMore bool, valuesOf func(*T) ([]any, error)) (*domain.CursorMeta, error) {
+	meta := &domain.CursorMeta{PageSize: page.Limit}
+	if len(items) == 0 {
+		return meta, nil
+	}
+
+	hasNext := hasMore
+	hasPrev := len(page.Values) > 0
+	if page.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	encode := func(item *T, backward bool) (string, error) {
+		values, err := valuesOf(item)
+		if err != nil {
+			return "", err
+		}
+		return codec.Encode(&util.CursorToken{
+			Sort:     sortSignature(sorts),
+			Values:   values,
+			Backward: backward,
+		})
+	}
+
+	var err error
+	if hasNext {
+		if meta.NextCursor, err = encode(&items[len(items)-1], false); err != nil {
+			return nil, err
+		}
+	}
+	if hasPrev {
+		if meta.PrevCursor, err = encode(&items[0], true); err != nil {
+			return nil, err
+		}
+	}
+
+	return meta, nil
+}
@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"math"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type DeadLetterRepository interface {
+	Create(ctx context.Context, deadLetter *entity.DeadLetter) error
+	Delete(ctx context.Context, deadLetter *entity.DeadLetter) error
+	Count(ctx context.Context, query string, args ...any) (int64, error)
+	FindByUUID(ctx context.Context, id uuid.UUID) (*entity.DeadLetter, error)
+	FindAll(ctx context.Context, jobName string, page, size int) ([]entity.DeadLetter, error)
+}
+
+// DeadLetterUsecase keeps jobs that failed permanently, so they can be
+// inspected and then retried or discarded.
+type DeadLetterUsecase struct {
+	TxManager      TxManager
+	Log            *logrus.Logger
+	DeadLetterRepo DeadLetterRepository
+	Outbox         *OutboxUsecase
+}
+
+func NewDeadLetterUsecase(r DeadLetterRepository, txManager TxManager, logger *logrus.Logger, outbox *OutboxUsecase) *DeadLetterUsecase {
+	return &DeadLetterUsecase{
+		TxManager:      txManager,
+		Log:            logger,
+		DeadLetterRepo: r,
+		Outbox:         outbox,
+	}
+}
+
+// Record stores job after its last attempt failed with cause.
+func (d *DeadLetterUsecase) Record(ctx context.Context, job *work.Job, cause error) error {
+	payload, err := json.Marshal(jobArgs(job.Args))
+	if err != nil {
+		return err
+	}
+
+	return d.DeadLetterRepo.Create(ctx, &entity.DeadLetter{
+		JobName:   job.Name,
+		JobID:     job.ID,
+		Payload:   payload,
+		Attempts:  int(job.Fails) + 1,
+		LastError: cause.Error(),
+		FailedAt:  time.Now(),
+	})
+}
+
+func (d *DeadLetterUsecase) FindAll(ctx context.Context, request *domain.DeadLetterListRequest) ([]*domain.DeadLetterResponse, *domain.PaginationMeta, error) {
+	deadLetters, err := d.DeadLetterRepo.FindAll(ctx, request.JobName, request.Page, request.Size)
+	if err != nil {
+		d.Log.WithError(err).Error("Failed to find dead letters")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.DeadLetterResponse, 0, len(deadLetters))
+	for i := range deadLetters {
+		responses = append(responses, converter.DeadLetterToResponse(&deadLetters[i]))
+	}
+
+	query, args := "1=1", []any{}
+	if request.JobName != "" {
+		query, args = "job_name = ?", []any{request.JobName}
+	}
+	totalCount, err := d.DeadLetterRepo.Count(ctx, query, args...)
+	if err != nil {
+		d.Log.WithError(err).Error("Failed to count dead letters")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	meta := &domain.PaginationMeta{
+		CurrentPage: request.Page,
+		TotalPages:  int(math.Ceil(float64(totalCount) / float64(request.Size))),
+		PageSize:    request.Size,
+		TotalCount:  totalCount,
+	}
+
+	return responses, meta, nil
+}
+
+func (d *DeadLetterUsecase) FindByUUID(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error) {
+	deadLetter, err := d.DeadLetterRepo.FindByUUID(ctx, request.UUID)
+	if err != nil {
+		d.Log.WithError(err).Error("Failed to find dead letter")
+		return nil, deadLetterLookupError(err)
+	}
+
+	return converter.DeadLetterToResponse(deadLetter), nil
+}
+
+// Retry queues the job again through the outbox with a fresh attempt count
+// and removes its dead letter.
+func (d *DeadLetterUsecase) Retry(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error) {
+	var deadLetter *entity.DeadLetter
+
+	err := d.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		deadLetter, err = d.DeadLetterRepo.FindByUUID(ctx, request.UUID)
+		if err != nil {
+			d.Log.WithError(err).Error("Failed to find dead letter")
+			return deadLetterLookupError(err)
+		}
+
+		args := work.Q{}
+		if err := json.Unmarshal(deadLetter.Payload, &args); err != nil {
+			d.Log.WithError(err).Error("Failed to decode dead letter payload")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if err := d.Outbox.Enqueue(ctx, deadLetter.JobName, args); err != nil {
+			d.Log.WithError(err).Error("Failed to requeue dead letter")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if err := d.DeadLetterRepo.Delete(ctx, deadLetter); err != nil {
+			d.Log.WithError(err).Error("Failed to delete dead letter")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.DeadLetterToResponse(deadLetter), nil
+}
+
+func (d *DeadLetterUsecase) Discard(ctx context.Context, request *domain.DeadLetterRequest) (*domain.DeadLetterResponse, error) {
+	deadLetter, err := d.DeadLetterRepo.FindByUUID(ctx, request.UUID)
+	if err != nil {
+		d.Log.WithError(err).Error("Failed to find dead letter")
+		return nil, deadLetterLookupError(err)
+	}
+
+	if err := d.DeadLetterRepo.Delete(ctx, deadLetter); err != nil {
+		d.Log.WithError(err).Error("Failed to delete dead letter")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return converter.DeadLetterToResponse(deadLetter), nil
+}
+
+// jobArgs drops the arguments added when the job was published, so a retry
+// is published as a new message.
+func jobArgs(args map[string]any) work.Q {
+	q := make(work.Q, len(args))
+	for key, value := range args {
+		if key == "outbox_id" {
+			continue
+		}
+		q[key] = value
+	}
+	return q
+}
+
+func deadLetterLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Dead letter not found")
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
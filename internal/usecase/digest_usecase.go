@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	JobDigestSweep = "digest_sweep"
+
+	digestSubscriberBatch = 100
+	digestSectionLimit    = 20
+)
+
+type DigestRepository interface {
+	FindSubscribers(ctx context.Context, afterUserID uint, limit int) ([]entity.UserPreference, error)
+	ClaimDigest(ctx context.Context, userID uint, slot, at time.Time) (bool, error)
+	FindOverdue(ctx context.Context, userID uint, now time.Time, limit int) ([]entity.Todo, error)
+	FindDueBetween(ctx context.Context, userID uint, from, to time.Time, limit int) ([]entity.Todo, error)
+	FindCompletedSince(ctx context.Context, userID uint, since time.Time, limit int) ([]entity.Todo, error)
+}
+
+type DigestUsecase struct {
+	TxManager  TxManager
+	Log        *logrus.Logger
+	DigestRepo DigestRepository
+	Notifier   *NotificationUsecase
+}
+
+func NewDigestUsecase(r DigestRepository, txManager TxManager, logger *logrus.Logger, notifier *NotificationUsecase) *DigestUsecase {
+	return &DigestUsecase{
+		TxManager:  txManager,
+		Log:        logger,
+		DigestRepo: r,
+		Notifier:   notifier,
+	}
+}
+
+// Sweep sends the digests whose latest slot has not been served yet. It runs
+// more often than hourly so that timezones with non-whole-hour offsets are
+// not served late.
+func (u *DigestUsecase) Sweep(ctx context.Context) error {
+	now := time.Now()
+
+	var after uint
+	for {
+		preferences, err := u.DigestRepo.FindSubscribers(ctx, after, digestSubscriberBatch)
+		if err != nil {
+			return err
+		}
+
+		for i := range preferences {
+			if err := u.send(ctx, &preferences[i], now); err != nil {
+				u.Log.WithError(err).Errorf("Failed to send digest to user %d", preferences[i].UserID)
+			}
+		}
+
+		if len(preferences) < digestSubscriberBatch {
+			return nil
+		}
+		after = preferences[len(preferences)-1].UserID
+	}
+}
+
+func (u *DigestUsecase) send(ctx context.Context, preference *entity.UserPreference, now time.Time) error {
+	location, err := loadTimezone(preference.Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	slot, period := digestSlot(preference, now.In(location))
+	if preference.LastDigestAt != nil && !preference.LastDigestAt.Before(slot) {
+		return nil
+	}
+
+	return u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := u.DigestRepo.ClaimDigest(ctx, preference.UserID, slot, now)
+		if err != nil || !claimed {
+			return err
+		}
+
+		local := now.In(location)
+		startOfToday := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, location)
+		startOfTomorrow := startOfToday.AddDate(0, 0, 1)
+
+		overdue, err := u.DigestRepo.FindOverdue(ctx, preference.UserID, now, digestSectionLimit)
+		if err != nil {
+			return err
+		}
+		dueToday, err := u.DigestRepo.FindDueBetween(ctx, preference.UserID, now, startOfTomorrow, digestSectionLimit)
+		if err != nil {
+			return err
+		}
+		dueThisWeek, err := u.DigestRepo.FindDueBetween(ctx, preference.UserID, startOfTomorrow, startOfToday.AddDate(0, 0, 7), digestSectionLimit)
+		if err != nil {
+			return err
+		}
+		completed, err := u.DigestRepo.FindCompletedSince(ctx, preference.UserID, slot.Add(-period), digestSectionLimit)
+		if err != nil {
+			return err
+		}
+
+		if len(overdue)+len(dueToday)+len(dueThisWeek)+len(completed) == 0 {
+			return nil
+		}
+
+		return u.Notifier.Send(ctx, preference.UserID, templates.KindDigest, digestEmailData{
+			Frequency:   preference.DigestFrequency,
+			Overdue:     digestItems(overdue),
+			DueToday:    digestItems(dueToday),
+			DueThisWeek: digestItems(dueThisWeek),
+			Completed:   digestItems(completed),
+		})
+	})
+}
+
+// digestSlot returns the most recent send time at or before local and the
+// length of the digest period.
+func digestSlot(preference *entity.UserPreference, local time.Time) (time.Time, time.Duration) {
+	slot := time.Date(local.Year(), local.Month(), local.Day(), preference.DigestHour, 0, 0, 0, local.Location())
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+
+	if preference.DigestFrequency != domain.DigestWeekly {
+		return slot, 24 * time.Hour
+	}
+	for slot.Weekday() != time.Weekday(preference.DigestWeekday) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	return slot, 7 * 24 * time.Hour
+}
+
+func digestItems(todos []entity.Todo) []digestItem {
+	items := make([]digestItem, 0, len(todos))
+	for _, todo := range todos {
+		items = append(items, digestItem{Title: todo.Title, DueTime: todo.DueTime})
+	}
+	return items
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"go-todo-api/internal/util"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const JobSendEmail = "send_email"
+
+type NotificationRepository interface {
+	FindUserByID(ctx context.Context, id uint) (*entity.User, error)
+	FindPreference(ctx context.Context, userID uint) (*entity.UserPreference, error)
+}
+
+// NotificationUsecase renders emails in the recipient's locale and timezone
+// and queues them for the send_email job through the outbox.
+type NotificationUsecase struct {
+	Log              *logrus.Logger
+	NotificationRepo NotificationRepository
+	Outbox           *OutboxUsecase
+}
+
+func NewNotificationUsecase(r NotificationRepository, logger *logrus.Logger, outbox *OutboxUsecase) *NotificationUsecase {
+	return &NotificationUsecase{
+		Log:              logger,
+		NotificationRepo: r,
+		Outbox:           outbox,
+	}
+}
+
+type todoEmailData struct {
+	Title       string
+	Description string
+	IsCompleted bool
+	DueTime     time.Time
+}
+
+func newTodoEmailData(todo *entity.Todo) todoEmailData {
+	return todoEmailData{
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		DueTime:     todo.DueTime,
+	}
+}
+
+type reminderEmailData struct {
+	Todo   todoEmailData
+	Amount int
+	Unit   string
+}
+
+type digestItem struct {
+	Title   string
+	DueTime time.Time
+}
+
+type digestEmailData struct {
+	Frequency   string
+	Overdue     []digestItem
+	DueToday    []digestItem
+	DueThisWeek []digestItem
+	Completed   []digestItem
+}
+
+// passwordResetEmailData links to the reset page; ExpiresIn is in minutes.
+type passwordResetEmailData struct {
+	URL       string
+	ExpiresIn int
+}
+
+// emailVerificationEmailData links to the verification page for Email;
+// ExpiresIn is in hours.
+type emailVerificationEmailData struct {
+	URL       string
+	Email     string
+	ExpiresIn int
+}
+
+// Send emails userID the kind notification rendered with data. Called inside
+// a transaction, the email is only sent if the transaction commits.
+func (n *NotificationUsecase) Send(ctx context.Context, userID uint, kind templates.Kind, data any) error {
+	return n.SendTo(ctx, userID, "", kind, data)
+}
+
+// SendTo is Send to the address to instead of the user's email, such as an
+// address the user has yet to verify. An empty to uses the user's email.
+func (n *NotificationUsecase) SendTo(ctx context.Context, userID uint, to string, kind templates.Kind, data any) error {
+	user, err := n.NotificationRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if to == "" {
+		to = user.Email
+	}
+
+	recipient, err := n.recipient(ctx, user)
+	if err != nil {
+		return err
+	}
+	recipient.Email = to
+
+	email, err := templates.Render(kind, recipient, data)
+	if err != nil {
+		n.Log.WithError(err).Errorf("Failed to render %s email", kind)
+		return err
+	}
+
+	err = n.Outbox.Enqueue(ctx, JobSendEmail, work.Q{
+		"to":      to,
+		"subject": email.Subject,
+		"body":    email.HTML,
+		"text":    email.Text,
+	})
+	if err != nil {
+		n.Log.WithError(err).Error("Failed to queue email in outbox")
+		return err
+	}
+	return nil
+}
+
+func (n *NotificationUsecase) recipient(ctx context.Context, user *entity.User) (templates.Recipient, error) {
+	recipient := templates.Recipient{
+		Name:     user.Name,
+		Email:    user.Email,
+		Locale:   templates.DefaultLocale,
+		Location: time.UTC,
+	}
+
+	preference, err := n.NotificationRepo.FindPreference(ctx, user.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return recipient, nil
+	}
+	if err != nil {
+		return recipient, err
+	}
+
+	recipient.Locale = preference.Locale
+	if location, err := loadTimezone(preference.Timezone); err == nil {
+		recipient.Location = location
+	}
+	return recipient, nil
+}
+
+// Preview renders kind with sample data for auth, in the requested locale.
+func (n *NotificationUsecase) Preview(ctx context.Context, auth *entity.User, request *domain.EmailPreviewRequest) (*domain.EmailPreviewResponse, error) {
+	kind := templates.Kind(request.Kind)
+	if !templates.IsKind(kind) {
+		return nil, util.NewCustomError(int(util.ErrNotFoundCode), "Email template not found")
+	}
+
+	locale := request.Locale
+	if locale == "" {
+		locale = templates.DefaultLocale
+	}
+	if !templates.IsLocale(locale) {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Unsupported locale "+locale)
+	}
+
+	recipient := templates.Recipient{Name: auth.Name, Email: auth.Email, Locale: locale, Location: time.UTC}
+	email, err := templates.Render(kind, recipient, previewData(kind, time.Now().UTC().Truncate(time.Hour)))
+	if err != nil {
+		n.Log.WithError(err).Errorf("Failed to render %s email", kind)
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return &domain.EmailPreviewResponse{
+		Kind:    string(kind),
+		Locale:  locale,
+		Subject: email.Subject,
+		HTML:    email.HTML,
+		Text:    email.Text,
+	}, nil
+}
+
+func previewData(kind templates.Kind, now time.Time) any {
+	todo := todoEmailData{
+		Title:       "Prepare sprint review",
+		Description: "Collect demo notes and <b>release</b> highlights",
+		DueTime:     now.Add(26 * time.Hour),
+	}
+
+	switch kind {
+	case templates.KindReminder:
+		return reminderEmailData{Todo: todo, Amount: 1, Unit: "day"}
+	case templates.KindOverdue:
+		todo.DueTime = now.Add(-2 * time.Hour)
+		return todo
+	case templates.KindDigest:
+		return digestEmailData{
+			Frequency:   domain.DigestDaily,
+			Overdue:     []digestItem{{Title: "Renew domain", DueTime: now.Add(-26 * time.Hour)}},
+			DueToday:    []digestItem{{Title: "Call the bank", DueTime: now.Add(3 * time.Hour)}},
+			DueThisWeek: []digestItem{{Title: todo.Title, DueTime: todo.DueTime}},
+			Completed:   []digestItem{{Title: "Book flights", DueTime: now.Add(-5 * time.Hour)}},
+		}
+	case templates.KindPasswordReset:
+		return passwordResetEmailData{
+			URL:       "https://example.com/reset-password?token=preview",
+			ExpiresIn: int(passwordResetTTL.Minutes()),
+		}
+	case templates.KindEmailVerification:
+		return emailVerificationEmailData{
+			URL:       "https://example.com/verify-email?token=preview",
+			Email:     "jane@example.com",
+			ExpiresIn: int(emailVerificationTTL.Hours()),
+		}
+	default:
+		return todo
+	}
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"go-todo-api/internal/entity"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// OutboxBatchSize is how many messages one relay pass publishes.
+	OutboxBatchSize = 100
+
+	outboxMaxBackoff = time.Hour
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, message *entity.OutboxMessage) error
+	LockPending(ctx context.Context, now time.Time, limit int) ([]entity.OutboxMessage, error)
+	MarkDispatched(ctx context.Context, id uint64, at time.Time) error
+	MarkFailed(ctx context.Context, id uint64, reason string, retryAt time.Time) error
+	DeleteDispatchedBefore(ctx context.Context, before time.Time) (int64, error)
+	DeletePending(ctx context.Context, id uuid.UUID) error
+	IsProcessed(ctx context.Context, id uuid.UUID) (bool, error)
+	MarkProcessed(ctx context.Context, id uuid.UUID, at time.Time) (bool, error)
+}
+
+// OutboxUsecase makes job publishing part of the database transaction that
+// causes it. Enqueue only writes an outbox row; Relay later publishes the
+// committed rows to the queue, so a rollback publishes nothing and a Redis
+// outage only delays delivery.
+//
+// It is the only publisher of jobs: usecases never hold the enqueuer, so no
+// job escapes a rollback. The worker pool's periodic sweeps are the one
+// exception, as they do not follow from a write.
+type OutboxUsecase struct {
+	TxManager  TxManager
+	Log        *logrus.Logger
+	OutboxRepo OutboxRepository
+	Enqueuer   *work.Enqueuer
+}
+
+func NewOutboxUsecase(r OutboxRepository, txManager TxManager, logger *logrus.Logger, enqueuer *work.Enqueuer) *OutboxUsecase {
+	return &OutboxUsecase{
+		TxManager:  txManager,
+		Log:        logger,
+		OutboxRepo: r,
+		Enqueuer:   enqueuer,
+	}
+}
+
+// Enqueue records jobName to be published with args. Called with a
+// transactional context, the row commits or rolls back with the transaction.
+func (o *OutboxUsecase) Enqueue(ctx context.Context, jobName string, args work.Q) error {
+	_, err := o.EnqueueAt(ctx, jobName, args, time.Now())
+	return err
+}
+
+// EnqueueAt is Enqueue for a job that is not published before at. It returns
+// the message UUID, with which Cancel withdraws the job until then.
+func (o *OutboxUsecase) EnqueueAt(ctx context.Context, jobName string, args work.Q, at time.Time) (uuid.UUID, error) {
+	payload, err := json.Marshal(args)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	message := &entity.OutboxMessage{
+		UUID:        uuid.New(),
+		JobName:     jobName,
+		Payload:     payload,
+		AvailableAt: at,
+	}
+	if err := o.OutboxRepo.Create(ctx, message); err != nil {
+		return uuid.Nil, err
+	}
+	return message.UUID, nil
+}
+
+// Cancel withdraws the message id if it has not been published yet. Called
+// with a transactional context, the job is only withdrawn if the transaction
+// commits.
+func (o *OutboxUsecase) Cancel(ctx context.Context, id uuid.UUID) error {
+	return o.OutboxRepo.DeletePending(ctx, id)
+}
+
+// Relay publishes one batch of due messages and reports how many it handled.
+// Each job carries the message UUID as outbox_id and is enqueued as unique,
+// so a message re-published after a crash is not queued twice while the
+// first copy is still waiting. A copy published after the first one ran is
+// skipped by its consumer, see MarkProcessed. Failed messages are retried
+// with exponential backoff.
+func (o *OutboxUsecase) Relay(ctx context.Context) (int, error) {
+	var handled int
+	err := o.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		now := time.Now()
+		messages, err := o.OutboxRepo.LockPending(ctx, now, OutboxBatchSize)
+		if err != nil {
+			return err
+		}
+		handled = len(messages)
+
+		for _, message := range messages {
+			if err := o.publish(&message); err != nil {
+				o.Log.WithError(err).Warnf("Failed to publish outbox message %d", message.ID)
+				retryAt := now.Add(outboxBackoff(message.Attempts + 1))
+				if err := o.OutboxRepo.MarkFailed(ctx, message.ID, err.Error(), retryAt); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := o.OutboxRepo.MarkDispatched(ctx, message.ID, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return handled, err
+}
+
+func (o *OutboxUsecase) publish(message *entity.OutboxMessage) error {
+	args := work.Q{}
+	if err := json.Unmarshal(message.Payload, &args); err != nil {
+		return err
+	}
+	args["outbox_id"] = message.UUID.String()
+
+	_, err := o.Enqueuer.EnqueueUnique(message.JobName, args)
+	return err
+}
+
+// Processed reports whether the job published from outboxID already ran.
+// Jobs without an outbox_id are never reported as processed.
+func (o *OutboxUsecase) Processed(ctx context.Context, outboxID string) (bool, error) {
+	id, err := uuid.Parse(outboxID)
+	if err != nil {
+		return false, nil
+	}
+	return o.OutboxRepo.IsProcessed(ctx, id)
+}
+
+// MarkProcessed records that the job published from outboxID ran, and
+// reports false if another copy of it already did. Consumers whose effects
+// are in the database call it in the same transaction, so a copy runs
+// exactly once; the others call Processed first and this after they
+// succeeded. Jobs without an outbox_id are always reported as new.
+func (o *OutboxUsecase) MarkProcessed(ctx context.Context, outboxID string) (bool, error) {
+	id, err := uuid.Parse(outboxID)
+	if err != nil {
+		return true, nil
+	}
+	return o.OutboxRepo.MarkProcessed(ctx, id, time.Now())
+}
+
+// Prune deletes messages dispatched longer than retention ago.
+func (o *OutboxUsecase) Prune(ctx context.Context, retention time.Duration) (int64, error) {
+	return o.OutboxRepo.DeleteDispatchedBefore(ctx, time.Now().Add(-retention))
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return outboxMaxBackoff
+	}
+	backoff := time.Duration(1<<attempts) * time.Second
+	if backoff > outboxMaxBackoff {
+		return outboxMaxBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,292 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"sort"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	JobTodoReminder  = "todo_reminder"
+	JobReminderSweep = "reminder_sweep"
+
+	// Reminders and overdue notices older than these windows are dropped
+	// rather than sent late.
+	reminderSweepWindow = time.Hour
+	overdueSweepWindow  = 24 * time.Hour
+	reminderSweepBatch  = 100
+)
+
+type ReminderRepository interface {
+	Create(ctx context.Context, reminder *entity.TodoReminder) error
+	Update(ctx context.Context, reminder *entity.TodoReminder) error
+	FindByID(ctx context.Context, id any) (*entity.TodoReminder, error)
+	FindByTodoID(ctx context.Context, todoID uint) ([]entity.TodoReminder, error)
+	DeleteByTodoID(ctx context.Context, todoID uint) error
+	MarkSent(ctx context.Context, id uint, at time.Time) (bool, error)
+	FindDue(ctx context.Context, from, to time.Time, limit int) ([]entity.TodoReminder, error)
+	FindOverdueTodos(ctx context.Context, from, to time.Time, limit int) ([]entity.Todo, error)
+	MarkOverdueNotified(ctx context.Context, todoID uint, at time.Time) (bool, error)
+	ResetOverdueNotice(ctx context.Context, todoID uint) error
+	FindTodoByID(ctx context.Context, id uint) (*entity.Todo, error)
+}
+
+// ReminderUsecase plans reminder jobs for todos and delivers them. Jobs go
+// through the outbox, so they are planned and withdrawn together with the
+// reminders, in the caller's transaction. Delivery also checks the reminder
+// is still current, so a job that was already published is harmless.
+type ReminderUsecase struct {
+	TxManager    TxManager
+	Log          *logrus.Logger
+	ReminderRepo ReminderRepository
+	Outbox       *OutboxUsecase
+	Notifier     *NotificationUsecase
+}
+
+func NewReminderUsecase(r ReminderRepository, txManager TxManager, logger *logrus.Logger, outbox *OutboxUsecase, notifier *NotificationUsecase) *ReminderUsecase {
+	return &ReminderUsecase{
+		TxManager:    txManager,
+		Log:          logger,
+		ReminderRepo: r,
+		Outbox:       outbox,
+		Notifier:     notifier,
+	}
+}
+
+func (u *ReminderUsecase) Find(ctx context.Context, todoID uint) ([]entity.TodoReminder, error) {
+	return u.ReminderRepo.FindByTodoID(ctx, todoID)
+}
+
+// Replace swaps the reminders of todo for one per distinct entry of
+// minutesBefore.
+func (u *ReminderUsecase) Replace(ctx context.Context, todo *entity.Todo, minutesBefore []int) ([]entity.TodoReminder, error) {
+	if err := u.Cancel(ctx, todo.ID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[int]bool, len(minutesBefore))
+	reminders := make([]entity.TodoReminder, 0, len(minutesBefore))
+	for _, minutes := range minutesBefore {
+		if seen[minutes] {
+			continue
+		}
+		seen[minutes] = true
+
+		reminder := entity.TodoReminder{
+			TodoID:        todo.ID,
+			MinutesBefore: minutes,
+			RemindAt:      todo.DueTime.Add(-time.Duration(minutes) * time.Minute),
+		}
+		if err := u.ReminderRepo.Create(ctx, &reminder); err != nil {
+			return nil, err
+		}
+
+		if !todo.IsCompleted {
+			if err := u.schedule(ctx, &reminder); err != nil {
+				return nil, err
+			}
+			if err := u.ReminderRepo.Update(ctx, &reminder); err != nil {
+				return nil, err
+			}
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].MinutesBefore > reminders[j].MinutesBefore
+	})
+	return reminders, nil
+}
+
+// Schedule re-plans the reminders of todo after its due time or completion
+// changed. Completed todos keep their reminders but nothing is scheduled.
+func (u *ReminderUsecase) Schedule(ctx context.Context, todo *entity.Todo) error {
+	reminders, err := u.ReminderRepo.FindByTodoID(ctx, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := range reminders {
+		reminder := &reminders[i]
+		if err := u.unschedule(ctx, reminder); err != nil {
+			return err
+		}
+		reminder.RemindAt = todo.DueTime.Add(-time.Duration(reminder.MinutesBefore) * time.Minute)
+		reminder.SentAt = nil
+
+		if !todo.IsCompleted {
+			if err := u.schedule(ctx, reminder); err != nil {
+				return err
+			}
+		}
+		if err := u.ReminderRepo.Update(ctx, reminder); err != nil {
+			return err
+		}
+	}
+
+	if len(reminders) > 0 && !todo.IsCompleted {
+		return u.ReminderRepo.ResetOverdueNotice(ctx, todo.ID)
+	}
+	return nil
+}
+
+// Cancel removes every reminder of a todo.
+func (u *ReminderUsecase) Cancel(ctx context.Context, todoID uint) error {
+	reminders, err := u.ReminderRepo.FindByTodoID(ctx, todoID)
+	if err != nil {
+		return err
+	}
+
+	for i := range reminders {
+		if err := u.unschedule(ctx, &reminders[i]); err != nil {
+			return err
+		}
+	}
+	return u.ReminderRepo.DeleteByTodoID(ctx, todoID)
+}
+
+// schedule plans the job for reminder through the outbox. Reminders whose
+// time has already passed are not sent.
+func (u *ReminderUsecase) schedule(ctx context.Context, reminder *entity.TodoReminder) error {
+	reminder.JobID, reminder.ScheduledFor = "", 0
+
+	if !reminder.RemindAt.After(time.Now()) {
+		return nil
+	}
+
+	id, err := u.Outbox.EnqueueAt(ctx, JobTodoReminder, work.Q{
+		"reminder_id": reminder.ID,
+		"remind_at":   reminder.RemindAt.Unix(),
+	}, reminder.RemindAt)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to schedule reminder")
+		return err
+	}
+
+	reminder.JobID = id.String()
+	reminder.ScheduledFor = reminder.RemindAt.Unix()
+	return nil
+}
+
+// unschedule withdraws the job of reminder if it is still in the outbox.
+func (u *ReminderUsecase) unschedule(ctx context.Context, reminder *entity.TodoReminder) error {
+	if reminder.JobID == "" {
+		return nil
+	}
+
+	id, err := uuid.Parse(reminder.JobID)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to parse scheduled reminder job")
+		return err
+	}
+	if err := u.Outbox.Cancel(ctx, id); err != nil {
+		u.Log.WithError(err).Error("Failed to withdraw scheduled reminder")
+		return err
+	}
+	reminder.JobID, reminder.ScheduledFor = "", 0
+	return nil
+}
+
+// DeliverReminder handles a reminder job. Jobs for reminders that were
+// re-planned, removed or already sent are dropped.
+func (u *ReminderUsecase) DeliverReminder(ctx context.Context, reminderID uint, remindAt int64) error {
+	reminder, err := u.ReminderRepo.FindByID(ctx, reminderID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if reminder.SentAt != nil || reminder.RemindAt.Unix() != remindAt {
+		return nil
+	}
+
+	return u.deliver(ctx, reminder)
+}
+
+// Sweep catches reminders whose job was lost and sends overdue notices for
+// todos with reminders once their deadline passes.
+func (u *ReminderUsecase) Sweep(ctx context.Context) error {
+	now := time.Now()
+
+	reminders, err := u.ReminderRepo.FindDue(ctx, now.Add(-reminderSweepWindow), now, reminderSweepBatch)
+	if err != nil {
+		return err
+	}
+	for i := range reminders {
+		if err := u.deliver(ctx, &reminders[i]); err != nil {
+			u.Log.WithError(err).Errorf("Failed to deliver reminder %d", reminders[i].ID)
+		}
+	}
+
+	todos, err := u.ReminderRepo.FindOverdueTodos(ctx, now.Add(-overdueSweepWindow), now, reminderSweepBatch)
+	if err != nil {
+		return err
+	}
+	for i := range todos {
+		if err := u.deliverOverdue(ctx, &todos[i]); err != nil {
+			u.Log.WithError(err).Errorf("Failed to deliver overdue notice for todo %d", todos[i].ID)
+		}
+	}
+
+	return nil
+}
+
+func (u *ReminderUsecase) deliver(ctx context.Context, reminder *entity.TodoReminder) error {
+	todo, err := u.ReminderRepo.FindTodoByID(ctx, reminder.TodoID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if todo.IsCompleted {
+		return nil
+	}
+
+	return u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := u.ReminderRepo.MarkSent(ctx, reminder.ID, time.Now())
+		if err != nil || !claimed {
+			return err
+		}
+
+		amount, unit := leadTime(reminder.MinutesBefore)
+		return u.Notifier.Send(ctx, todo.UserID, templates.KindReminder, reminderEmailData{
+			Todo:   newTodoEmailData(todo),
+			Amount: amount,
+			Unit:   unit,
+		})
+	})
+}
+
+func (u *ReminderUsecase) deliverOverdue(ctx context.Context, todo *entity.Todo) error {
+	return u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		claimed, err := u.ReminderRepo.MarkOverdueNotified(ctx, todo.ID, time.Now())
+		if err != nil || !claimed {
+			return err
+		}
+
+		return u.Notifier.Send(ctx, todo.UserID, templates.KindOverdue, newTodoEmailData(todo))
+	})
+}
+
+// leadTime splits minutes into the largest whole unit.
+func leadTime(minutes int) (int, string) {
+	switch {
+	case minutes%1440 == 0:
+		return minutes / 1440, "day"
+	case minutes%60 == 0:
+		return minutes / 60, "hour"
+	default:
+		return minutes, "minute"
+	}
+}
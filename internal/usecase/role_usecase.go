@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	Create(ctx context.Context, role *entity.Role) error
+	FindByID(ctx context.Context, id any) (*entity.Role, error)
+	Delete(ctx context.Context, role *entity.Role) error
+	FindAllRole(ctx context.Context) ([]entity.Role, error)
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+	FindPermissionsByRoleID(ctx context.Context, roleID uint) ([]string, error)
+	AssignUser(ctx context.Context, roleID, userID uint) error
+	UnassignUser(ctx context.Context, roleID, userID uint) error
+	UnassignUsers(ctx context.Context, roleID uint) error
+}
+
+// TwoFactorPolicyRepository stores which built-in roles require two-factor
+// authentication.
+type TwoFactorPolicyRepository interface {
+	RequiresTwoFactor(ctx context.Context, role string) (bool, error)
+	FindTwoFactorRoles(ctx context.Context) ([]string, error)
+	ReplaceTwoFactorRoles(ctx context.Context, roles []string) error
+}
+
+type RoleUsecase struct {
+	TxManager     TxManager
+	Log           *logrus.Logger
+	RoleRepo      RoleRepository
+	TwoFactorRepo TwoFactorPolicyRepository
+}
+
+func NewRoleUsecase(r RoleRepository, twoFactorRepo TwoFactorPolicyRepository, txManager TxManager, logger *logrus.Logger) *RoleUsecase {
+	return &RoleUsecase{
+		TxManager:     txManager,
+		Log:           logger,
+		RoleRepo:      r,
+		TwoFactorRepo: twoFactorRepo,
+	}
+}
+
+func (r *RoleUsecase) Create(ctx context.Context, request *domain.RoleCreateRequest) (*domain.RoleResponse, error) {
+	if _, builtin := BuiltinRolePermissions[request.Name]; builtin {
+		return nil, util.NewCustomError(int(util.ErrConflictCode), "Role name is reserved")
+	}
+
+	existingRole, _ := r.RoleRepo.FindByName(ctx, request.Name)
+	if existingRole != nil {
+		r.Log.Warnf("Role %s already exists", request.Name)
+		return nil, util.NewCustomError(int(util.ErrConflictCode), "Role with name already exists")
+	}
+
+	role := &entity.Role{Name: request.Name}
+	seen := make(map[string]bool)
+	for _, permission := range request.Permissions {
+		if !domain.IsKnownPermission(permission) {
+			return nil, util.NewCustomError(int(util.ErrBadRequestCode), fmt.Sprintf("Unknown permission %q", permission))
+		}
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		role.Permissions = append(role.Permissions, entity.RolePermission{Permission: permission})
+	}
+
+	if err := r.RoleRepo.Create(ctx, role); err != nil {
+		r.Log.WithError(err).Error("Failed to create role")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return converter.RoleToResponse(role), nil
+}
+
+func (r *RoleUsecase) Delete(ctx context.Context, request *domain.RoleDeleteRequest) (*domain.RoleResponse, error) {
+	var role *entity.Role
+
+	err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		role, err = r.RoleRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			r.Log.WithError(err).Error("Failed to found role")
+			return roleLookupError(err)
+		}
+
+		if err := r.RoleRepo.UnassignUsers(ctx, role.ID); err != nil {
+			r.Log.WithError(err).Error("Failed to unassign users from role")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if err := r.RoleRepo.Delete(ctx, role); err != nil {
+			r.Log.WithError(err).Error("Failed to delete role")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.RoleToResponse(role), nil
+}
+
+func (r *RoleUsecase) FindAllRole(ctx context.Context) ([]*domain.RoleResponse, error) {
+	roles, err := r.RoleRepo.FindAllRole(ctx)
+	if err != nil {
+		r.Log.WithError(err).Error("Failed to find roles")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		responses = append(responses, converter.RoleToResponse(&role))
+	}
+
+	return responses, nil
+}
+
+func (r *RoleUsecase) AssignUser(ctx context.Context, request *domain.RoleAssignRequest) error {
+	role, err := r.RoleRepo.FindByID(ctx, request.ID)
+	if err != nil {
+		r.Log.WithError(err).Error("Failed to found role")
+		return roleLookupError(err)
+	}
+
+	if err := r.RoleRepo.AssignUser(ctx, role.ID, request.UserID); err != nil {
+		r.Log.WithError(err).Error("Failed to assign role to user")
+		return userLookupError(err)
+	}
+
+	return nil
+}
+
+func (r *RoleUsecase) UnassignUser(ctx context.Context, request *domain.RoleAssignRequest) error {
+	if err := r.RoleRepo.UnassignUser(ctx, request.ID, request.UserID); err != nil {
+		r.Log.WithError(err).Error("Failed to unassign role from user")
+		return userLookupError(err)
+	}
+
+	return nil
+}
+
+func roleLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Role not found")
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
+
+func userLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "User not found")
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
+
+func (r *RoleUsecase) TwoFactorPolicy(ctx context.Context) (*domain.TwoFactorPolicyResponse, error) {
+	roles, err := r.TwoFactorRepo.FindTwoFactorRoles(ctx)
+	if err != nil {
+		r.Log.WithError(err).Error("Failed to find two-factor policy")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return &domain.TwoFactorPolicyResponse{Roles: roles}, nil
+}
+
+// UpdateTwoFactorPolicy makes the built-in roles of request the ones that
+// require two-factor authentication.
+func (r *RoleUsecase) UpdateTwoFactorPolicy(ctx context.Context, request *domain.TwoFactorPolicyRequest) (*domain.TwoFactorPolicyResponse, error) {
+	seen := make(map[string]bool)
+	roles := make([]string, 0, len(request.Roles))
+	for _, role := range request.Roles {
+		if _, builtin := BuiltinRolePermissions[role]; !builtin {
+			return nil, util.NewCustomError(int(util.ErrBadRequestCode), fmt.Sprintf("Unknown built-in role %q", role))
+		}
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		roles = append(roles, role)
+	}
+
+	err := r.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := r.TwoFactorRepo.ReplaceTwoFactorRoles(ctx, roles); err != nil {
+			r.Log.WithError(err).Error("Failed to update two-factor policy")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return r.TwoFactorPolicy(ctx)
+}
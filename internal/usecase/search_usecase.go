@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"math"
+
+	"github.com/sirupsen/logrus"
+)
+
+type SearchRepository interface {
+	Search(ctx context.Context, scope *domain.TodoScope, query, searchType string, offset, limit int) ([]entity.SearchResult, error)
+	Count(ctx context.Context, scope *domain.TodoScope, query, searchType string) (int64, error)
+}
+
+type SearchUsecase struct {
+	Log        *logrus.Logger
+	SearchRepo SearchRepository
+	Authz      *AuthorizationUsecase
+}
+
+func NewSearchUsecase(s SearchRepository, logger *logrus.Logger, authz *AuthorizationUsecase) *SearchUsecase {
+	return &SearchUsecase{
+		Log:        logger,
+		SearchRepo: s,
+		Authz:      authz,
+	}
+}
+
+func (s *SearchUsecase) Search(ctx context.Context, auth *entity.User, request *domain.SearchRequest) ([]*domain.SearchResponse, *domain.PaginationMeta, error) {
+	scope, err := resolveTodoScope(ctx, s.Authz, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	offset := (request.Page - 1) * request.Size
+	results, err := s.SearchRepo.Search(ctx, scope, request.Query, request.Type, offset, request.Size)
+	if err != nil {
+		s.Log.WithError(err).Error("Failed to search todos and tags")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	totalCount, err := s.SearchRepo.Count(ctx, scope, request.Query, request.Type)
+	if err != nil {
+		s.Log.WithError(err).Error("Failed to count search results")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.SearchResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, converter.SearchResultToResponse(&result))
+	}
+
+	meta := &domain.PaginationMeta{
+		CurrentPage: request.Page,
+		TotalPages:  int(math.Ceil(float64(totalCount) / float64(request.Size))),
+		PageSize:    request.Size,
+		TotalCount:  totalCount,
+	}
+
+	return responses, meta, nil
+}
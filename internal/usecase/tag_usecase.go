@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"go-todo-api/domain"
 	"go-todo-api/domain/converter"
 	"go-todo-api/internal/config"
@@ -16,108 +17,122 @@ import (
 type TagRepository interface {
 	Create(ctx context.Context, tag *entity.Tag) error
 	FindByID(ctx context.Context, id any) (*entity.Tag, error)
-	Update(ctx context.Context, tag *entity.Tag) error
-	Delete(ctx context.Context, tag *entity.Tag) error
-	FindAllTag(ctx context.Context, offset, limit int) (*[]entity.Tag, error)
+	UpdateTag(ctx context.Context, tag *entity.Tag) error
+	DeleteTag(ctx context.Context, tag *entity.Tag) error
+	FindAllTag(ctx context.Context, page, size int) (*[]entity.Tag, error)
+	FindAllTagByCursor(ctx context.Context, cursor *domain.CursorPage) ([]entity.Tag, bool, error)
+	CursorValues(ctx context.Context, tag *entity.Tag, sorts []domain.SortKey) ([]any, error)
 	Count(ctx context.Context, query string, args ...any) (int64, error)
 }
 
 type TagUsecase struct {
-	DB         *gorm.DB
+	TxManager  TxManager
 	Log        *logrus.Logger
 	JwtService *config.JwtConfig
 	TagRepo    TagRepository
+	Cursor     *util.CursorCodec
 }
 
-func NewTagUsecase(t TagRepository, db *gorm.DB, logger *logrus.Logger, jwtService *config.JwtConfig) *TagUsecase {
+func NewTagUsecase(t TagRepository, txManager TxManager, logger *logrus.Logger, jwtService *config.JwtConfig, cursor *util.CursorCodec) *TagUsecase {
 	return &TagUsecase{
-		DB:         db,
+		TxManager:  txManager,
 		Log:        logger,
 		TagRepo:    t,
 		JwtService: jwtService,
+		Cursor:     cursor,
 	}
 }
 
 func (t *TagUsecase) Create(ctx context.Context, requests []*domain.TagCreateRequest) ([]*domain.TagResponse, error) {
-	var (
-		tx   = t.DB.WithContext(ctx).Begin()
-		tags []*domain.TagResponse
-	)
+	var tags []*domain.TagResponse
 
-	for _, request := range requests {
-		tag := entity.Tag{
-			Name: request.Name,
-		}
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, request := range requests {
+			tag := entity.Tag{
+				Name: request.Name,
+			}
 
-		if err := t.TagRepo.Create(tx.Statement.Context, &tag); err != nil {
-			t.Log.WithError(err).Error("Failed to create user")
-			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-		}
+			if err := t.TagRepo.Create(ctx, &tag); err != nil {
+				t.Log.WithError(err).Error("Failed to create tag")
+				return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
+			}
 
-		tags = append(tags, converter.TagToResponse(&tag))
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		t.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			tags = append(tags, converter.TagToResponse(&tag))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
 	return tags, nil
 }
 
 func (t *TagUsecase) Update(ctx context.Context, request *domain.TagUpdateRequest) (*domain.TagResponse, error) {
-	tx := t.DB.WithContext(ctx).Begin()
-
-	tag, err := t.TagRepo.FindByID(tx.Statement.Context, request.ID)
-	if err != nil {
-		t.Log.WithError(err).Error("Failed to found tag")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+	var tag *entity.Tag
+
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		tag, err = t.TagRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to found tag")
+			return tagLookupError(err)
+		}
 
-	if request.Name != "" {
-		tag.Name = request.Name
-	}
-	if err := t.TagRepo.Update(tx.Statement.Context, tag); err != nil {
-		t.Log.WithError(err).Error("Failed to update todo")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+		if err := checkVersion(request.Version, tag.Version, "Tag"); err != nil {
+			return err
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		t.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		if request.Name != "" {
+			tag.Name = request.Name
+		}
+		if err := t.TagRepo.UpdateTag(ctx, tag); err != nil {
+			t.Log.WithError(err).Error("Failed to update tag")
+			return tagLookupError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
 	return converter.TagToResponse(tag), nil
 }
 
 func (t *TagUsecase) Delete(ctx context.Context, request *domain.TagDeleteRequest) (*domain.TagResponse, error) {
-	tx := t.DB.WithContext(ctx).Begin()
+	var tag *entity.Tag
+
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		tag, err = t.TagRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to found tag")
+			return tagLookupError(err)
+		}
 
-	tag, err := t.TagRepo.FindByID(tx.Statement.Context, request.ID)
-	if err != nil {
-		t.Log.WithError(err).Error("Failed to found tag")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+		if err := checkVersion(request.Version, tag.Version, "Tag"); err != nil {
+			return err
+		}
 
-	if err := t.TagRepo.Delete(tx.Statement.Context, tag); err != nil {
-		t.Log.WithError(err).Error("Failed to update todo")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		if err := t.TagRepo.DeleteTag(ctx, tag); err != nil {
+			t.Log.WithError(err).Error("Failed to delete tag")
+			return tagLookupError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
-	if err := tx.Commit().Error; err != nil {
-		t.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
 	return converter.TagToResponse(tag), nil
 }
 
-func (t *TagUsecase) FindAllTag(ctx context.Context, page, size int) ([]*domain.TagResponse, *domain.PaginationMeta, error) {
+func (t *TagUsecase) FindAllTag(ctx context.Context, request *domain.TagListRequest) ([]*domain.TagResponse, *domain.PaginationMeta, error) {
 	var (
 		tags          []entity.Tag
 		tagsResponses []*domain.TagResponse
+		page          = request.Page
+		size          = request.Size
 	)
 	tagsFromRepo, err := t.TagRepo.FindAllTag(ctx, page, size)
 	if err != nil {
@@ -148,12 +163,53 @@ func (t *TagUsecase) FindAllTag(ctx context.Context, page, size int) ([]*domain.
 	return tagsResponses, meta, nil
 }
 
+func (t *TagUsecase) FindAllTagByCursor(ctx context.Context, request *domain.TagListRequest) ([]*domain.TagResponse, *domain.CursorMeta, error) {
+	page, err := decodeCursorPage(t.Cursor, request.Cursor, nil, request.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags, hasMore, err := t.TagRepo.FindAllTagByCursor(ctx, page)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find tags")
+		if errors.Is(err, util.ErrInvalidCursor) {
+			return nil, nil, util.NewCustomError(int(util.ErrBadRequestCode), "Invalid cursor")
+		}
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	tagsResponses := make([]*domain.TagResponse, 0, len(tags))
+	for _, tag := range tags {
+		tagsResponses = append(tagsResponses, converter.TagToResponse(&tag))
+	}
+
+	meta, err := buildCursorMeta(t.Cursor, nil, page, tags, hasMore, func(tag *entity.Tag) ([]any, error) {
+		return t.TagRepo.CursorValues(ctx, tag, nil)
+	})
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to build tag cursors")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return tagsResponses, meta, nil
+}
+
 func (t *TagUsecase) FindTagById(ctx context.Context, request *domain.TagGetDataRequest) (*domain.TagResponse, error) {
 	tag, err := t.TagRepo.FindByID(ctx, request.ID)
 	if err != nil {
 		t.Log.WithError(err).Error("Failed to found tag")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		return nil, tagLookupError(err)
 	}
 
 	return converter.TagToResponse(tag), nil
 }
+
+func tagLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Tag not found")
+	}
+	if errors.Is(err, util.ErrVersionConflict) {
+		return modifiedError("Tag")
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"go-todo-api/internal/util"
+
+	"github.com/google/uuid"
+)
+
+// Patch applies a JSON Merge Patch or JSON Patch to the current state of a
+// todo. Fields left out of the patch keep their stored value.
+func (t *TodoUsecase) Patch(ctx context.Context, auth *entity.User, request *domain.TodoPatchRequest) (*domain.TodoResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *entity.Todo
+	err = t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		todo, err = t.TodoRepo.FindTodoByID(ctx, scope, request.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to found todo")
+			return todoLookupError(err)
+		}
+
+		if err := checkVersion(request.Version, todo.Version, "Todo"); err != nil {
+			return err
+		}
+
+		currentTags, err := t.TodoRepo.FindTagsByTodoID(ctx, todo.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to find todo tags")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		doc, err := applyTodoPatch(todo, currentTags, request)
+		if err != nil {
+			return err
+		}
+
+		tags, err := t.resolvePatchTags(ctx, doc.Tags)
+		if err != nil {
+			return err
+		}
+
+		wasCompleted, oldDue := todo.IsCompleted, todo.DueTime
+		todo.Title = doc.Title
+		todo.Description = doc.Description
+		todo.IsCompleted = doc.IsCompleted
+		todo.DueTime = doc.DueTime
+
+		if doc.Recurrence != todo.Recurrence {
+			if err := setRecurrence(todo, doc.Recurrence); err != nil {
+				return err
+			}
+		}
+
+		if err := t.TodoRepo.UpdateTodo(ctx, scope, todo); err != nil {
+			t.Log.WithError(err).Error("Failed to update todo")
+			return todoLookupError(err)
+		}
+
+		if err := t.applySubtaskRules(ctx, todo, wasCompleted); err != nil {
+			return err
+		}
+
+		if err := t.syncTags(ctx, scope, todo.ID, tags); err != nil {
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		todo.Tag = tags
+
+		if err := t.rescheduleReminders(ctx, todo, oldDue, wasCompleted); err != nil {
+			return err
+		}
+
+		if err := t.scheduleNextOccurrence(ctx, todo, wasCompleted); err != nil {
+			return err
+		}
+
+		return t.notify(ctx, todo, templates.KindTodoUpdated)
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.TodoToResponse(todo), nil
+}
+
+func applyTodoPatch(todo *entity.Todo, tags []entity.Tag, request *domain.TodoPatchRequest) (*domain.TodoPatchDocument, error) {
+	current := domain.TodoPatchDocument{
+		Title:       todo.Title,
+		Description: todo.Description,
+		IsCompleted: todo.IsCompleted,
+		DueTime:     todo.DueTime,
+		Recurrence:  todo.Recurrence,
+		Tags:        make([]uuid.UUID, 0, len(tags)),
+	}
+	for _, tag := range tags {
+		current.Tags = append(current.Tags, tag.UUID)
+	}
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	var patched []byte
+	switch request.Kind {
+	case domain.PatchKindMerge:
+		patched, err = util.MergePatch(raw, request.Patch)
+	case domain.PatchKindJSON:
+		patched, err = util.JSONPatch(raw, request.Patch)
+	default:
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Unsupported patch format")
+	}
+	if err != nil {
+		return nil, patchError(err)
+	}
+
+	var doc domain.TodoPatchDocument
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Patched todo is invalid: "+err.Error())
+	}
+
+	if ok, err := util.IsRequestValid(&doc); !ok {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), err.Error())
+	}
+
+	return &doc, nil
+}
+
+func patchError(err error) error {
+	switch {
+	case errors.Is(err, util.ErrInvalidPatch):
+		return util.NewCustomError(int(util.ErrBadRequestCode), err.Error())
+	case errors.Is(err, util.ErrPatchConflict):
+		return util.NewCustomError(int(util.ErrConflictCode), err.Error())
+	default:
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+}
+
+// resolvePatchTags loads the tags named in a patched document, collapsing
+// duplicates since a todo's tags form a set.
+func (t *TodoUsecase) resolvePatchTags(ctx context.Context, uuids []uuid.UUID) ([]entity.Tag, error) {
+	seen := make(map[uuid.UUID]bool, len(uuids))
+	unique := make([]uuid.UUID, 0, len(uuids))
+	for _, id := range uuids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	tags, err := t.TodoRepo.FindTagsByUUIDs(ctx, unique)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find tags")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if len(tags) != len(unique) {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Patched todo references an unknown tag")
+	}
+
+	return tags, nil
+}
+
+// syncTags makes the todo's tag links match tags exactly.
+func (t *TodoUsecase) syncTags(ctx context.Context, scope *domain.TodoScope, todoID uint, tags []entity.Tag) error {
+	existing, err := t.TodoRepo.FindTodoTagByTodoID(ctx, scope, todoID)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find todo_tags")
+		return err
+	}
+
+	wanted := make(map[uint]bool, len(tags))
+	tagIDs := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		wanted[tag.ID] = true
+		tagIDs = append(tagIDs, int(tag.ID))
+	}
+
+	var stale []entity.TodoTag
+	for _, todoTag := range existing {
+		if !wanted[todoTag.TagID] {
+			stale = append(stale, todoTag)
+		}
+	}
+
+	if len(stale) > 0 {
+		if err := t.TodoRepo.DeleteTodoTag(ctx, scope, stale); err != nil {
+			t.Log.WithError(err).Error("Failed to delete todo_tags")
+			return err
+		}
+	}
+
+	return t.attachTags(ctx, scope, todoID, tagIDs)
+}
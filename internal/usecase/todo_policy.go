@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+
+	"gorm.io/gorm"
+)
+
+// resolveTodoScope decides which todos the authenticated user may act on.
+// Every caller needs the "own" permission, and leaving their own rows through
+// the explicit all users mode additionally needs the matching "any" permission.
+func resolveTodoScope(ctx context.Context, authz *AuthorizationUsecase, auth *entity.User, allUsers bool, ownPerm, anyPerm domain.Permission) (*domain.TodoScope, error) {
+	if err := authz.Authorize(ctx, auth, ownPerm); err != nil {
+		return nil, err
+	}
+
+	if allUsers {
+		if err := authz.Authorize(ctx, auth, anyPerm); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.TodoScope{
+		UserID:   auth.ID,
+		AllUsers: allUsers,
+	}, nil
+}
+
+func (t *TodoUsecase) todoScope(ctx context.Context, auth *entity.User, allUsers bool, ownPerm, anyPerm domain.Permission) (*domain.TodoScope, error) {
+	return resolveTodoScope(ctx, t.Authz, auth, allUsers, ownPerm, anyPerm)
+}
+
+// requireVerified refuses to create todos for auth while the email
+// verification policy is on and auth has not verified their email.
+func (t *TodoUsecase) requireVerified(auth *entity.User) error {
+	if t.VerifiedOnly && auth.VerifiedAt == nil {
+		return util.NewCustomError(int(util.ErrForbiddenCode), "Verify your email address before creating todos")
+	}
+	return nil
+}
+
+// todoLookupError hides todos owned by someone else behind a plain 404.
+func todoLookupError(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Todo not found")
+	}
+	if errors.Is(err, util.ErrVersionConflict) {
+		return modifiedError("Todo")
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
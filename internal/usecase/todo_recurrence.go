@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"sort"
+	"time"
+)
+
+const maxOccurrencesPerTodo = 100
+
+// setRecurrence validates rule and anchors the series at the todo's current
+// due time. An empty rule stops the todo from recurring.
+func setRecurrence(todo *entity.Todo, rule string) error {
+	if rule == "" {
+		todo.Recurrence = ""
+		todo.RecurrenceStart = nil
+		return nil
+	}
+
+	if todo.ParentID != nil {
+		return util.NewCustomError(int(util.ErrBadRequestCode), "Subtasks cannot recur")
+	}
+	if _, err := util.ParseRRule(rule); err != nil {
+		return util.NewCustomError(int(util.ErrBadRequestCode), err.Error())
+	}
+
+	start := todo.DueTime
+	todo.Recurrence = rule
+	todo.RecurrenceStart = &start
+	return nil
+}
+
+// scheduleNextOccurrence creates the next occurrence of a recurring todo the
+// first time it is completed, copying its tags and reminders. Subtasks are not copied.
+func (t *TodoUsecase) scheduleNextOccurrence(ctx context.Context, todo *entity.Todo, wasCompleted bool) error {
+	if todo.Recurrence == "" || !todo.IsCompleted || wasCompleted || todo.NextOccurrenceID != nil {
+		return nil
+	}
+
+	rule, err := util.ParseRRule(todo.Recurrence)
+	if err != nil {
+		t.Log.WithError(err).Error("Stored recurrence rule is invalid")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	start := todo.DueTime
+	if todo.RecurrenceStart != nil {
+		start = *todo.RecurrenceStart
+	}
+
+	next, ok := rule.After(start, todo.DueTime)
+	if !ok {
+		return nil
+	}
+
+	tags, err := t.TodoRepo.FindTagsByTodoID(ctx, todo.ID)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find todo tags")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	occurrence := &entity.Todo{
+		UserID:          todo.UserID,
+		Title:           todo.Title,
+		Description:     todo.Description,
+		DueTime:         next,
+		Recurrence:      todo.Recurrence,
+		RecurrenceStart: &start,
+	}
+	if err := t.TodoRepo.Create(ctx, occurrence); err != nil {
+		t.Log.WithError(err).Error("Failed to create next occurrence")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	tagIDs := make([]int, 0, len(tags))
+	for _, tag := range tags {
+		tagIDs = append(tagIDs, int(tag.ID))
+	}
+	if err := t.attachTags(ctx, &domain.TodoScope{UserID: todo.UserID}, occurrence.ID, tagIDs); err != nil {
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	if err := t.copyReminders(ctx, todo, occurrence); err != nil {
+		t.Log.WithError(err).Error("Failed to copy reminders to next occurrence")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	if err := t.TodoRepo.SetNextOccurrence(ctx, todo.ID, occurrence.ID); err != nil {
+		t.Log.WithError(err).Error("Failed to link next occurrence")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	todo.NextOccurrenceID = &occurrence.ID
+
+	return nil
+}
+
+// FindOccurrences expands the open recurring todos into their occurrences
+// within [From, To).
+func (t *TodoUsecase) FindOccurrences(ctx context.Context, auth *entity.User, request *domain.TodoOccurrenceRequest) ([]*domain.TodoOccurrenceResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, err
+	}
+
+	todos, err := t.TodoRepo.FindRecurring(ctx, scope)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find recurring todos")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	occurrences := make([]*domain.TodoOccurrenceResponse, 0)
+	for _, todo := range todos {
+		rule, err := util.ParseRRule(todo.Recurrence)
+		if err != nil {
+			t.Log.WithError(err).Warnf("Skipping todo %d with invalid recurrence", todo.ID)
+			continue
+		}
+
+		start := todo.DueTime
+		if todo.RecurrenceStart != nil {
+			start = *todo.RecurrenceStart
+		}
+
+		occurrence := func(due time.Time, projected bool) *domain.TodoOccurrenceResponse {
+			return &domain.TodoOccurrenceResponse{
+				TodoUUID:   todo.UUID,
+				Title:      todo.Title,
+				DueTime:    due,
+				Recurrence: todo.Recurrence,
+				Projected:  projected,
+			}
+		}
+
+		if !todo.DueTime.Before(request.From) && todo.DueTime.Before(request.To) {
+			occurrences = append(occurrences, occurrence(todo.DueTime, false))
+		}
+		for _, due := range rule.Between(start, request.From, request.To, maxOccurrencesPerTodo) {
+			if due.After(todo.DueTime) {
+				occurrences = append(occurrences, occurrence(due, true))
+			}
+		}
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].DueTime.Before(occurrences[j].DueTime)
+	})
+
+	return occurrences, nil
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"time"
+)
+
+func (t *TodoUsecase) SetReminders(ctx context.Context, auth *entity.User, request *domain.ReminderSetRequest) ([]*domain.ReminderResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var reminders []entity.TodoReminder
+	err = t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		todo, err := t.TodoRepo.FindTodoByID(ctx, scope, request.TodoID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to found todo")
+			return todoLookupError(err)
+		}
+
+		reminders, err = t.Reminders.Replace(ctx, todo, request.MinutesBefore)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to set reminders")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return remindersToResponse(reminders), nil
+}
+
+func (t *TodoUsecase) FindReminders(ctx context.Context, auth *entity.User, request *domain.ReminderListRequest) ([]*domain.ReminderResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := t.TodoRepo.FindTodoByID(ctx, scope, request.TodoID)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to found todo")
+		return nil, todoLookupError(err)
+	}
+
+	reminders, err := t.Reminders.Find(ctx, todo.ID)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find reminders")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return remindersToResponse(reminders), nil
+}
+
+// rescheduleReminders re-plans the reminders of todo when a write moved its
+// due time or changed its completion.
+func (t *TodoUsecase) rescheduleReminders(ctx context.Context, todo *entity.Todo, oldDue time.Time, wasCompleted bool) error {
+	if todo.DueTime.Equal(oldDue) && todo.IsCompleted == wasCompleted {
+		return nil
+	}
+
+	if err := t.Reminders.Schedule(ctx, todo); err != nil {
+		t.Log.WithError(err).Error("Failed to reschedule reminders")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return nil
+}
+
+// copyReminders gives occurrence the same reminder offsets as todo.
+func (t *TodoUsecase) copyReminders(ctx context.Context, todo, occurrence *entity.Todo) error {
+	reminders, err := t.Reminders.Find(ctx, todo.ID)
+	if err != nil || len(reminders) == 0 {
+		return err
+	}
+
+	minutes := make([]int, 0, len(reminders))
+	for _, reminder := range reminders {
+		minutes = append(minutes, reminder.MinutesBefore)
+	}
+	_, err = t.Reminders.Replace(ctx, occurrence, minutes)
+	return err
+}
+
+func remindersToResponse(reminders []entity.TodoReminder) []*domain.ReminderResponse {
+	responses := make([]*domain.ReminderResponse, 0, len(reminders))
+	for i := range reminders {
+		responses = append(responses, converter.ReminderToResponse(&reminders[i]))
+	}
+	return responses
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"math"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Subtasks are todos with a parent_id, one level deep. The parent and its
+// subtasks are kept consistent as follows:
+//   - completing the parent completes every open subtask;
+//   - an open subtask (new or reopened) reopens its parent;
+//   - deleting the parent deletes its subtasks;
+//   - any change to a subtask bumps the parent's version, so the parent's
+//     ETag follows its progress roll-up.
+
+func (t *TodoUsecase) CreateSubtask(ctx context.Context, auth *entity.User, request *domain.SubtaskCreateRequest) (*domain.TodoResponse, error) {
+	if err := t.Authz.Authorize(ctx, auth, domain.PermTodoCreate); err != nil {
+		return nil, err
+	}
+	if err := t.requireVerified(auth); err != nil {
+		return nil, err
+	}
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtask *entity.Todo
+	err = t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		parent, err := t.findSubtaskParent(ctx, scope, request.ParentID)
+		if err != nil {
+			return err
+		}
+
+		position, err := t.TodoRepo.NextSubtaskPosition(ctx, parent.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to find subtask position")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		dueTime := parent.DueTime
+		if request.DueTime != nil {
+			dueTime = *request.DueTime
+		}
+
+		subtask = &entity.Todo{
+			UserID:      parent.UserID,
+			ParentID:    &parent.ID,
+			Position:    position,
+			Title:       request.Title,
+			Description: request.Description,
+			DueTime:     dueTime,
+		}
+		if err := t.TodoRepo.Create(ctx, subtask); err != nil {
+			t.Log.WithError(err).Error("Failed to create subtask")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		return t.applySubtaskRules(ctx, subtask, false)
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.TodoToResponse(subtask), nil
+}
+
+func (t *TodoUsecase) FindSubtasks(ctx context.Context, auth *entity.User, request *domain.SubtaskListRequest) ([]*domain.TodoResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, err := t.findSubtaskParent(ctx, scope, request.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	subtasks, err := t.TodoRepo.FindSubtasks(ctx, scope, parent.ID)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find subtasks")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.TodoResponse, 0, len(subtasks))
+	for _, subtask := range subtasks {
+		responses = append(responses, converter.TodoToResponse(&subtask))
+	}
+	return responses, nil
+}
+
+// ReorderSubtasks sets subtask positions from request.Order, which must list
+// every subtask of the parent exactly once.
+func (t *TodoUsecase) ReorderSubtasks(ctx context.Context, auth *entity.User, request *domain.SubtaskReorderRequest) ([]*domain.TodoResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var responses []*domain.TodoResponse
+	err = t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		parent, err := t.findSubtaskParent(ctx, scope, request.ParentID)
+		if err != nil {
+			return err
+		}
+
+		subtasks, err := t.TodoRepo.FindSubtasks(ctx, scope, parent.ID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to find subtasks")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		byUUID := make(map[uuid.UUID]*entity.Todo, len(subtasks))
+		for i := range subtasks {
+			byUUID[subtasks[i].UUID] = &subtasks[i]
+		}
+		if len(request.Order) != len(subtasks) {
+			return util.NewCustomError(int(util.ErrBadRequestCode), "Order must list every subtask exactly once")
+		}
+
+		responses = make([]*domain.TodoResponse, 0, len(request.Order))
+		for i, id := range request.Order {
+			subtask, ok := byUUID[id]
+			if !ok {
+				return util.NewCustomError(int(util.ErrBadRequestCode), "Order must list every subtask exactly once")
+			}
+			delete(byUUID, id)
+
+			if subtask.Position != i+1 {
+				if err := t.TodoRepo.SetSubtaskPosition(ctx, subtask.ID, i+1); err != nil {
+					t.Log.WithError(err).Error("Failed to reorder subtask")
+					return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+				}
+				subtask.Position = i + 1
+				subtask.Version++
+			}
+			responses = append(responses, converter.TodoToResponse(subtask))
+		}
+
+		if err := t.TodoRepo.TouchTodo(ctx, parent.ID, false); err != nil {
+			t.Log.WithError(err).Error("Failed to touch parent todo")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return responses, nil
+}
+
+func (t *TodoUsecase) CompleteSubtask(ctx context.Context, auth *entity.User, request *domain.SubtaskCompleteRequest) (*domain.TodoResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtask *entity.Todo
+	err = t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		parent, err := t.findSubtaskParent(ctx, scope, request.ParentID)
+		if err != nil {
+			return err
+		}
+
+		subtask, err = t.TodoRepo.FindSubtaskByUUID(ctx, scope, parent.ID, request.SubtaskUUID)
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to find subtask")
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return util.NewCustomError(int(util.ErrNotFoundCode), "Subtask not found")
+			}
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if subtask.IsCompleted == request.IsCompleted {
+			return nil
+		}
+
+		subtask.IsCompleted = request.IsCompleted
+		if err := t.TodoRepo.UpdateTodo(ctx, scope, subtask); err != nil {
+			t.Log.WithError(err).Error("Failed to update subtask")
+			return todoLookupError(err)
+		}
+
+		if err := t.applySubtaskRules(ctx, subtask, !request.IsCompleted); err != nil {
+			return err
+		}
+		return t.rescheduleReminders(ctx, subtask, subtask.DueTime, !request.IsCompleted)
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.TodoToResponse(subtask), nil
+}
+
+func (t *TodoUsecase) findSubtaskParent(ctx context.Context, scope *domain.TodoScope, id uint) (*entity.Todo, error) {
+	parent, err := t.TodoRepo.FindTodoByID(ctx, scope, id)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to found todo")
+		return nil, todoLookupError(err)
+	}
+	if parent.ParentID != nil {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Subtasks cannot have subtasks of their own")
+	}
+	return parent, nil
+}
+
+// applySubtaskRules runs after todo was written, wasCompleted being its
+// completion state before the write.
+func (t *TodoUsecase) applySubtaskRules(ctx context.Context, todo *entity.Todo, wasCompleted bool) error {
+	var err error
+	switch {
+	case todo.ParentID != nil:
+		err = t.TodoRepo.TouchTodo(ctx, *todo.ParentID, !todo.IsCompleted)
+	case todo.IsCompleted && !wasCompleted:
+		err = t.TodoRepo.CompleteSubtasks(ctx, todo.ID)
+	}
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to apply subtask rules")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return nil
+}
+
+// attachProgress fills in the subtask roll-up of todos that have subtasks.
+// responses and todos must line up index for index.
+func (t *TodoUsecase) attachProgress(ctx context.Context, todos []entity.Todo, responses []*domain.TodoResponse) error {
+	ids := make([]uint, 0, len(todos))
+	for _, todo := range todos {
+		if todo.ParentID == nil {
+			ids = append(ids, todo.ID)
+		}
+	}
+
+	progress, err := t.TodoRepo.SubtaskProgress(ctx, ids)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to count subtasks")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	for i, todo := range todos {
+		p, ok := progress[todo.ID]
+		if !ok || p.Total == 0 {
+			continue
+		}
+		p.Percentage = math.Round(float64(p.Completed)/float64(p.Total)*10000) / 100
+		responses[i].Progress = &p
+	}
+	return nil
+}
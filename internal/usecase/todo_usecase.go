@@ -2,258 +2,314 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"go-todo-api/domain"
 	"go-todo-api/domain/converter"
 	"go-todo-api/internal/config"
 	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
 	"go-todo-api/internal/util"
 	"math"
 
-	"github.com/gocraft/work"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
-	"gorm.io/gorm"
 )
 
 type TodoRepository interface {
 	Create(ctx context.Context, todo *entity.Todo) error
-	FindByID(ctx context.Context, id any) (*entity.Todo, error)
-	Update(ctx context.Context, todo *entity.Todo) error
-	Delete(ctx context.Context, todo *entity.Todo) error
-	FindAll(ctx context.Context, offset, limit int) (*[]entity.Todo, error)
-	FindAllWithPagination(ctx context.Context, offset, limit int) (*[]entity.Todo, error)
-	Count(ctx context.Context, query string, args ...any) (int64, error)
+	FindTodoByID(ctx context.Context, scope *domain.TodoScope, id any) (*entity.Todo, error)
+	UpdateTodo(ctx context.Context, scope *domain.TodoScope, todo *entity.Todo) error
+	DeleteTodo(ctx context.Context, scope *domain.TodoScope, todo *entity.Todo) error
+	FindAll(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter, sorts []domain.SortKey, page, size int) (*[]entity.Todo, error)
+	FindAllByCursor(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter, sorts []domain.SortKey, cursor *domain.CursorPage) ([]entity.Todo, bool, error)
+	CursorValues(ctx context.Context, todo *entity.Todo, sorts []domain.SortKey) ([]any, error)
+	CountTodo(ctx context.Context, scope *domain.TodoScope, filter *domain.TodoFilter) (int64, error)
 	CreateTodoTag(ctx context.Context, todoTag *entity.TodoTag) error
-	FindTodoTagByTodoID(ctx context.Context, todoID uint) ([]entity.TodoTag, error)
-	DeleteTodoTag(ctx context.Context, todoTags []entity.TodoTag) error
-	FindTodoTagByTagID(ctx context.Context, tagID uint) ([]entity.TodoTag, error)
-	FindUserById(ctx context.Context, id any) (*entity.User, error)
+	FindTodoTagByTodoID(ctx context.Context, scope *domain.TodoScope, todoID uint) ([]entity.TodoTag, error)
+	DeleteTodoTag(ctx context.Context, scope *domain.TodoScope, todoTags []entity.TodoTag) error
+	FindTodoTag(ctx context.Context, scope *domain.TodoScope, todoID, tagID uint) ([]entity.TodoTag, error)
+	FindTagsByTodoID(ctx context.Context, todoID uint) ([]entity.Tag, error)
+	FindTagsByUUIDs(ctx context.Context, uuids []uuid.UUID) ([]entity.Tag, error)
+	FindSubtasks(ctx context.Context, scope *domain.TodoScope, parentID uint) ([]entity.Todo, error)
+	FindSubtaskByUUID(ctx context.Context, scope *domain.TodoScope, parentID uint, id uuid.UUID) (*entity.Todo, error)
+	NextSubtaskPosition(ctx context.Context, parentID uint) (int, error)
+	SetSubtaskPosition(ctx context.Context, id uint, position int) error
+	CompleteSubtasks(ctx context.Context, parentID uint) error
+	DeleteSubtasks(ctx context.Context, parentID uint) error
+	TouchTodo(ctx context.Context, id uint, reopen bool) error
+	SubtaskProgress(ctx context.Context, parentIDs []uint) (map[uint]domain.TodoProgress, error)
+	FindRecurring(ctx context.Context, scope *domain.TodoScope) ([]entity.Todo, error)
+	SetNextOccurrence(ctx context.Context, id, nextID uint) error
 }
 
 type TodoUsecase struct {
-	DB         *gorm.DB
+	TxManager  TxManager
 	Log        *logrus.Logger
 	JwtService *config.JwtConfig
 	TodoRepo   TodoRepository
-	Enqueuer   *work.Enqueuer
+	Notifier   *NotificationUsecase
+	Authz      *AuthorizationUsecase
+	Cursor     *util.CursorCodec
+	Reminders  *ReminderUsecase
+
+	// VerifiedOnly refuses new todos from users whose email is not verified.
+	VerifiedOnly bool
 }
 
-func NewTodoUseCase(t TodoRepository, db *gorm.DB, logger *logrus.Logger, jwtService *config.JwtConfig, enqueuer *work.Enqueuer) *TodoUsecase {
+func NewTodoUseCase(t TodoRepository, txManager TxManager, logger *logrus.Logger, jwtService *config.JwtConfig, notifier *NotificationUsecase, authz *AuthorizationUsecase, cursor *util.CursorCodec, reminders *ReminderUsecase, verifiedOnly bool) *TodoUsecase {
 	return &TodoUsecase{
-		DB:         db,
-		Log:        logger,
-		TodoRepo:   t,
-		JwtService: jwtService,
-		Enqueuer:   enqueuer,
+		TxManager:    txManager,
+		Log:          logger,
+		TodoRepo:     t,
+		JwtService:   jwtService,
+		Notifier:     notifier,
+		Authz:        authz,
+		Cursor:       cursor,
+		Reminders:    reminders,
+		VerifiedOnly: verifiedOnly,
 	}
 }
 
-func (t *TodoUsecase) enqueueEmail(to string, todo *entity.Todo, status string) error {
-	subject := fmt.Sprintf("Your new todo \"%s\" has been %s successfully.", todo.Title, status)
-	body := fmt.Sprintf(`
-    <html>
-        <body>
-            <h2>Your new todo "<strong>%s</strong>" has been  %s successfully.</h2>
-            <p><strong>Status Completed:</strong> %v</p>
-            <p><strong>Description:</strong> %s</p>
-            <p><strong>Due Time:</strong> %s</p>
-        </body>
-    </html>
-    `, todo.Title, status, todo.IsCompleted, todo.Description, todo.DueTime)
-
-	_, err := t.Enqueuer.Enqueue("send_email", work.Q{
-		"to":      to,
-		"subject": subject,
-		"body":    body,
-	})
-	if err != nil {
-		t.Log.WithError(err).Error("Failed to enqueue email task")
-		return err
+// notify emails the owner of todo once the surrounding transaction commits.
+func (t *TodoUsecase) notify(ctx context.Context, todo *entity.Todo, kind templates.Kind) error {
+	if err := t.Notifier.Send(ctx, todo.UserID, kind, newTodoEmailData(todo)); err != nil {
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
 	}
-
-	t.Log.Info("Email task enqueued successfully")
 	return nil
 }
 
-func (t *TodoUsecase) Create(ctx context.Context, requests []*domain.TodoCreateRequest) ([]*domain.TodoResponse, error) {
-	var (
-		tx    = t.DB.WithContext(ctx).Begin()
-		todos []*domain.TodoResponse
-	)
+func (t *TodoUsecase) attachTags(ctx context.Context, scope *domain.TodoScope, todoID uint, tagIDs []int) error {
+	for _, tagID := range tagIDs {
+		todoTags, err := t.TodoRepo.FindTodoTag(ctx, scope, todoID, uint(tagID))
+		if err != nil {
+			t.Log.WithError(err).Error("Failed to check existing todo_tag")
+			return err
+		}
 
-	for _, request := range requests {
-		todo := entity.Todo{
-			Title:       request.Title,
-			UserID:      request.UserID,
-			Description: request.Description,
-			IsCompleted: request.IsCompleted,
-			DueTime:     request.DueTime,
+		if len(todoTags) > 0 {
+			continue
 		}
 
-		if err := t.TodoRepo.Create(tx.Statement.Context, &todo); err != nil {
-			t.Log.WithError(err).Error("Failed to create user")
-			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		newTodoTag := &entity.TodoTag{
+			TodoID: todoID,
+			TagID:  uint(tagID),
 		}
 
-		for _, tagID := range request.TagID {
-			todoTag, err := t.TodoRepo.FindTodoTagByTagID(tx.Statement.Context, uint(tagID))
-			if err != nil {
-				t.Log.WithError(err).Error("Failed to check existing todo_tag")
-				return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-			}
+		if err := t.TodoRepo.CreateTodoTag(ctx, newTodoTag); err != nil {
+			t.Log.WithError(err).Error("Failed to create todo_tag")
+			return err
+		}
+	}
+
+	return nil
+}
 
-			if todoTag != nil {
-				continue
+func (t *TodoUsecase) Create(ctx context.Context, auth *entity.User, requests []*domain.TodoCreateRequest) ([]*domain.TodoResponse, error) {
+	if err := t.Authz.Authorize(ctx, auth, domain.PermTodoCreate); err != nil {
+		return nil, err
+	}
+	if err := t.requireVerified(auth); err != nil {
+		return nil, err
+	}
+	scope := &domain.TodoScope{UserID: auth.ID}
+
+	var todos []*domain.TodoResponse
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, request := range requests {
+			todo := entity.Todo{
+				Title:       request.Title,
+				UserID:      scope.UserID,
+				Description: request.Description,
+				IsCompleted: request.IsCompleted,
+				DueTime:     request.DueTime,
 			}
 
-			newTodoTag := &entity.TodoTag{
-				TodoID: todo.ID,
-				TagID:  uint(tagID),
+			if err := setRecurrence(&todo, request.Recurrence); err != nil {
+				return util.NewItemError(i, err)
 			}
 
-			if err := t.TodoRepo.CreateTodoTag(tx.Statement.Context, newTodoTag); err != nil {
-				t.Log.WithError(err).Error("Failed to create todo_tag")
-				tx.Rollback()
-				return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			if err := t.TodoRepo.Create(ctx, &todo); err != nil {
+				t.Log.WithError(err).Error("Failed to create todo")
+				return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
 			}
-		}
 
-		user, _ := t.TodoRepo.FindUserById(ctx, todo.UserID)
-		if err := t.enqueueEmail(user.Email, &todo, "created"); err != nil {
-			t.Log.WithError(err).Error("Failed to enqueue email after creating todo")
-		}
+			if err := t.attachTags(ctx, scope, todo.ID, request.TagID); err != nil {
+				return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
+			}
 
-		todos = append(todos, converter.TodoToResponse(&todo))
-	}
+			if err := t.notify(ctx, &todo, templates.KindTodoCreated); err != nil {
+				return util.NewItemError(i, err)
+			}
 
-	if err := tx.Commit().Error; err != nil {
-		t.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			todos = append(todos, converter.TodoToResponse(&todo))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to create todos")
+		return nil, txError(err)
 	}
 
 	return todos, nil
 }
 
-func (t *TodoUsecase) Update(ctx context.Context, requests []*domain.TodoUpdateRequest) ([]*domain.TodoResponse, error) {
-	var (
-		tx    = t.DB.WithContext(ctx).Begin()
-		todos []*domain.TodoResponse
-	)
+func (t *TodoUsecase) Update(ctx context.Context, auth *entity.User, requests []*domain.TodoUpdateRequest) ([]*domain.TodoResponse, error) {
+	var todos []*domain.TodoResponse
 
-	for _, request := range requests {
-		todo, err := t.TodoRepo.FindByID(tx.Statement.Context, request.ID)
-		if err != nil {
-			t.Log.WithError(err).Error("Failed to found todo")
-			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-		}
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, request := range requests {
+			scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoUpdateOwn, domain.PermTodoUpdateAny)
+			if err != nil {
+				return util.NewItemError(i, err)
+			}
 
-		if request.Title != "" {
-			todo.Title = request.Title
-		}
+			todo, err := t.TodoRepo.FindTodoByID(ctx, scope, request.ID)
+			if err != nil {
+				t.Log.WithError(err).Error("Failed to found todo")
+				return util.NewItemError(i, todoLookupError(err))
+			}
 
-		if request.Description != "" {
-			todo.Description = request.Description
-		}
-		todo.IsCompleted = request.IsCompleted
-		todo.DueTime = request.DueTime
+			if err := checkVersion(request.Version, todo.Version, "Todo"); err != nil {
+				return util.NewItemError(i, err)
+			}
 
-		if err := t.TodoRepo.Update(tx.Statement.Context, todo); err != nil {
-			t.Log.WithError(err).Error("Failed to update todo")
-			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-		}
+			wasCompleted, oldDue := todo.IsCompleted, todo.DueTime
+			if request.Title != "" {
+				todo.Title = request.Title
+			}
 
-		if len(request.TagID) > 0 {
-			existingTags, err := t.TodoRepo.FindTodoTagByTodoID(tx.Statement.Context, todo.ID)
-			if err != nil {
-				t.Log.WithError(err).Error("Failed to find todo_tags")
-				tx.Rollback()
-				return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			if request.Description != "" {
+				todo.Description = request.Description
 			}
+			todo.IsCompleted = request.IsCompleted
+			todo.DueTime = request.DueTime
 
-			if len(existingTags) > 0 {
-				if err := t.TodoRepo.DeleteTodoTag(tx.Statement.Context, existingTags); err != nil {
-					t.Log.WithError(err).Error("Failed to delete todo_tags")
-					tx.Rollback()
-					return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			if request.Recurrence != nil {
+				if err := setRecurrence(todo, *request.Recurrence); err != nil {
+					return util.NewItemError(i, err)
 				}
 			}
 
-			for _, tagID := range request.TagID {
-				todoTag, err := t.TodoRepo.FindTodoTagByTagID(tx.Statement.Context, uint(tagID))
+			if err := t.TodoRepo.UpdateTodo(ctx, scope, todo); err != nil {
+				t.Log.WithError(err).Error("Failed to update todo")
+				return util.NewItemError(i, todoLookupError(err))
+			}
+
+			if err := t.applySubtaskRules(ctx, todo, wasCompleted); err != nil {
+				return util.NewItemError(i, err)
+			}
+
+			if len(request.TagID) > 0 {
+				existingTags, err := t.TodoRepo.FindTodoTagByTodoID(ctx, scope, todo.ID)
 				if err != nil {
-					t.Log.WithError(err).Error("Failed to check existing todo_tag")
-					return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+					t.Log.WithError(err).Error("Failed to find todo_tags")
+					return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
 				}
 
-				if todoTag != nil {
-					continue
+				if len(existingTags) > 0 {
+					if err := t.TodoRepo.DeleteTodoTag(ctx, scope, existingTags); err != nil {
+						t.Log.WithError(err).Error("Failed to delete todo_tags")
+						return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
+					}
 				}
 
-				newTodoTag := &entity.TodoTag{
-					TodoID: todo.ID,
-					TagID:  uint(tagID),
+				if err := t.attachTags(ctx, scope, todo.ID, request.TagID); err != nil {
+					return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
 				}
+			}
 
-				if err := t.TodoRepo.CreateTodoTag(tx.Statement.Context, newTodoTag); err != nil {
-					t.Log.WithError(err).Error("Failed to create todo_tag")
-					tx.Rollback()
-					return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-				}
+			if err := t.rescheduleReminders(ctx, todo, oldDue, wasCompleted); err != nil {
+				return util.NewItemError(i, err)
 			}
-		}
 
-		user, _ := t.TodoRepo.FindUserById(ctx, todo.UserID)
-		if err := t.enqueueEmail(user.Email, todo, "updated"); err != nil {
-			t.Log.WithError(err).Error("Failed to enqueue email after updated todo")
-		}
+			if err := t.scheduleNextOccurrence(ctx, todo, wasCompleted); err != nil {
+				return util.NewItemError(i, err)
+			}
 
-		todos = append(todos, converter.TodoToResponse(todo))
-	}
+			if err := t.notify(ctx, todo, templates.KindTodoUpdated); err != nil {
+				return util.NewItemError(i, err)
+			}
 
-	if err := tx.Commit().Error; err != nil {
-		t.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			todos = append(todos, converter.TodoToResponse(todo))
+		}
+		return nil
+	})
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to update todos")
+		return nil, txError(err)
 	}
 
 	return todos, nil
 }
 
-func (t *TodoUsecase) Delete(ctx context.Context, request *domain.TodoDeleteRequest) ([]*domain.TodoResponse, error) {
-	var (
-		tx           = t.DB.WithContext(ctx).Begin()
-		deletedTodos []*domain.TodoResponse
-	)
+func (t *TodoUsecase) Delete(ctx context.Context, auth *entity.User, requests []*domain.TodoDeleteRequest) ([]*domain.TodoResponse, error) {
+	var deletedTodos []*domain.TodoResponse
 
-	todo, err := t.TodoRepo.FindByID(tx.Statement.Context, request.ID)
-	if err != nil {
-		t.Log.WithError(err).Error("Failed to found todo")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+	err := t.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i, request := range requests {
+			scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoDeleteOwn, domain.PermTodoDeleteAny)
+			if err != nil {
+				return util.NewItemError(i, err)
+			}
 
-	err = t.TodoRepo.Delete(ctx, todo)
-	if err != nil {
-		t.Log.WithError(err).Error("Error deleting todo")
-		return nil, fmt.Errorf("failed to delete todo: %w", err)
-	}
+			todo, err := t.TodoRepo.FindTodoByID(ctx, scope, request.ID)
+			if err != nil {
+				t.Log.WithError(err).Error("Failed to found todo")
+				return util.NewItemError(i, todoLookupError(err))
+			}
+
+			if err := checkVersion(request.Version, todo.Version, "Todo"); err != nil {
+				return util.NewItemError(i, err)
+			}
+
+			if err := t.TodoRepo.DeleteTodo(ctx, scope, todo); err != nil {
+				t.Log.WithError(err).Error("Error deleting todo")
+				return util.NewItemError(i, todoLookupError(err))
+			}
+
+			if err := t.Reminders.Cancel(ctx, todo.ID); err != nil {
+				t.Log.WithError(err).Error("Failed to cancel reminders")
+				return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
+			}
+
+			if todo.ParentID != nil {
+				err = t.TodoRepo.TouchTodo(ctx, *todo.ParentID, false)
+			} else {
+				err = t.TodoRepo.DeleteSubtasks(ctx, todo.ID)
+			}
+			if err != nil {
+				t.Log.WithError(err).Error("Failed to apply subtask rules")
+				return util.NewItemError(i, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error()))
+			}
 
-	user, _ := t.TodoRepo.FindUserById(ctx, todo.UserID)
-	if err := t.enqueueEmail(user.Email, todo, "deleted"); err != nil {
-		t.Log.WithError(err).Error("Failed to enqueue email after deleted todo")
+			if err := t.notify(ctx, todo, templates.KindTodoDeleted); err != nil {
+				return util.NewItemError(i, err)
+			}
+			deletedTodos = append(deletedTodos, converter.TodoUUIDToResponse(todo))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
-	deletedTodos = append(deletedTodos, converter.TodoUUIDToResponse(todo))
 
 	return deletedTodos, nil
 }
 
-func (t *TodoUsecase) FindAllTodo(ctx context.Context, page, size int) ([]*domain.TodoResponse, *domain.PaginationMeta, error) {
+func (t *TodoUsecase) FindAllTodo(ctx context.Context, auth *entity.User, request *domain.TodoListRequest) ([]*domain.TodoResponse, *domain.PaginationMeta, error) {
 	var (
 		todos         []entity.Todo
 		todoResponses []*domain.TodoResponse
+		page          = request.Page
+		size          = request.Size
 	)
 
-	todosFromRepo, err := t.TodoRepo.FindAll(ctx, page, size)
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todosFromRepo, err := t.TodoRepo.FindAll(ctx, scope, &request.Filter, request.Sort, page, size)
 	if err != nil {
 		t.Log.WithError(err).Error("Failed to find todos")
 		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
@@ -265,7 +321,11 @@ func (t *TodoUsecase) FindAllTodo(ctx context.Context, page, size int) ([]*domai
 		todoResponses = append(todoResponses, converter.TodoToResponse(&todo))
 	}
 
-	totalCount, err := t.TodoRepo.Count(ctx, "1=1")
+	if err := t.attachProgress(ctx, todos, todoResponses); err != nil {
+		return nil, nil, err
+	}
+
+	totalCount, err := t.TodoRepo.CountTodo(ctx, scope, &request.Filter)
 	if err != nil {
 		t.Log.WithError(err).Error("Failed to count todos")
 		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
@@ -283,12 +343,62 @@ func (t *TodoUsecase) FindAllTodo(ctx context.Context, page, size int) ([]*domai
 	return todoResponses, meta, nil
 }
 
-func (t *TodoUsecase) FindTodoByID(ctx context.Context, request *domain.TodoGetDataRequest) (*domain.TodoResponse, error) {
-	todo, err := t.TodoRepo.FindByID(ctx, request.ID)
+func (t *TodoUsecase) FindAllTodoByCursor(ctx context.Context, auth *entity.User, request *domain.TodoListRequest) ([]*domain.TodoResponse, *domain.CursorMeta, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	page, err := decodeCursorPage(t.Cursor, request.Cursor, request.Sort, request.Size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todos, hasMore, err := t.TodoRepo.FindAllByCursor(ctx, scope, &request.Filter, request.Sort, page)
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to find todos")
+		if errors.Is(err, util.ErrInvalidCursor) {
+			return nil, nil, util.NewCustomError(int(util.ErrBadRequestCode), "Invalid cursor")
+		}
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	todoResponses := make([]*domain.TodoResponse, 0, len(todos))
+	for _, todo := range todos {
+		todoResponses = append(todoResponses, converter.TodoToResponse(&todo))
+	}
+
+	if err := t.attachProgress(ctx, todos, todoResponses); err != nil {
+		return nil, nil, err
+	}
+
+	meta, err := buildCursorMeta(t.Cursor, request.Sort, page, todos, hasMore, func(todo *entity.Todo) ([]any, error) {
+		return t.TodoRepo.CursorValues(ctx, todo, request.Sort)
+	})
+	if err != nil {
+		t.Log.WithError(err).Error("Failed to build todo cursors")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return todoResponses, meta, nil
+}
+
+func (t *TodoUsecase) FindTodoByID(ctx context.Context, auth *entity.User, request *domain.TodoGetDataRequest) (*domain.TodoResponse, error) {
+	scope, err := t.todoScope(ctx, auth, request.AllUsers, domain.PermTodoReadOwn, domain.PermTodoReadAny)
+	if err != nil {
+		return nil, err
+	}
+
+	todo, err := t.TodoRepo.FindTodoByID(ctx, scope, request.ID)
 	if err != nil {
 		t.Log.WithError(err).Error("Failed to find todos")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		return nil, todoLookupError(err)
+	}
+
+	response := converter.TodoToResponse(todo)
+	if err := t.attachProgress(ctx, []entity.Todo{*todo}, []*domain.TodoResponse{response}); err != nil {
+		return nil, err
 	}
 
-	return converter.TodoToResponse(todo), nil
+	return response, nil
 }
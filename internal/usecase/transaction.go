@@ -0,0 +1,26 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/internal/util"
+)
+
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txError keeps the item or custom error returned from inside a unit of work
+// and reports anything else, such as a failed commit, as an internal error.
+func txError(err error) error {
+	var itemErr *util.ItemError
+	if errors.As(err, &itemErr) {
+		return itemErr
+	}
+
+	var customErr *util.CustomError
+	if errors.As(err, &customErr) {
+		return customErr
+	}
+	return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+}
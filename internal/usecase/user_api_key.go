@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// API keys read as apiKeyPrefix, then the public key prefix and the
+	// secret separated by an underscore.
+	apiKeyPrefix      = "gta_"
+	maxAPIKeysPerUser = 20
+	// LastUsedAt is kept to this precision to avoid a write per request.
+	apiKeyTouchInterval = time.Minute
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+	FindByUserID(ctx context.Context, userID uint) ([]entity.APIKey, error)
+	FindByPrefix(ctx context.Context, prefix string) (*entity.APIKey, error)
+	Touch(ctx context.Context, id uint64, at, since time.Time) error
+	DeleteByUUID(ctx context.Context, userID uint, id uuid.UUID) (bool, error)
+	DeleteByUserID(ctx context.Context, userID uint) error
+}
+
+// CreateAPIKey issues a key limited to the requested scopes. Its secret is
+// only part of this response.
+func (u *UserUsecase) CreateAPIKey(ctx context.Context, request *domain.APIKeyCreateRequest) (*domain.APIKeyResponse, error) {
+	now := time.Now()
+	if request.ExpiresAt != nil && !request.ExpiresAt.After(now) {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "API key expiry must be in the future")
+	}
+
+	key := &entity.APIKey{
+		UserID:    request.ID,
+		Name:      request.Name,
+		ExpiresAt: request.ExpiresAt,
+	}
+	seen := make(map[string]bool)
+	for _, scope := range request.Scopes {
+		if !domain.IsKnownPermission(scope) {
+			return nil, util.NewCustomError(int(util.ErrBadRequestCode), fmt.Sprintf("Unknown scope %q", scope))
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		key.Scopes = append(key.Scopes, entity.APIKeyScope{Scope: scope})
+	}
+
+	prefix, err := util.NewKeyPrefix()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate API key prefix")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	secret, err := util.NewOpaqueToken()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate API key secret")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	key.Prefix = prefix
+	key.SecretHash = util.HashToken(secret)
+
+	err = u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		count, err := u.APIKeyRepo.CountByUserID(ctx, request.ID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to count API keys")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if count >= maxAPIKeysPerUser {
+			return util.NewCustomError(int(util.ErrConflictCode), fmt.Sprintf("A user can have at most %d API keys", maxAPIKeysPerUser))
+		}
+
+		if err := u.APIKeyRepo.Create(ctx, key); err != nil {
+			u.Log.WithError(err).Error("Failed to create API key")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	response := converter.APIKeyToResponse(key)
+	response.Key = apiKeyPrefix + prefix + "_" + secret
+	return response, nil
+}
+
+func (u *UserUsecase) APIKeys(ctx context.Context, request *domain.APIKeyListRequest) ([]*domain.APIKeyResponse, error) {
+	keys, err := u.APIKeyRepo.FindByUserID(ctx, request.ID)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to find API keys")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		responses = append(responses, converter.APIKeyToResponse(&keys[i]))
+	}
+
+	return responses, nil
+}
+
+func (u *UserUsecase) RevokeAPIKey(ctx context.Context, request *domain.APIKeyRevokeRequest) error {
+	deleted, err := u.APIKeyRepo.DeleteByUUID(ctx, request.ID, request.UUID)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to delete API key")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if !deleted {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "API key not found")
+	}
+
+	return nil
+}
+
+// AuthenticateAPIKey returns the user of an unexpired key, with Scopes set
+// to the permissions the key may use.
+func (u *UserUsecase) AuthenticateAPIKey(ctx context.Context, rawKey string) (*entity.User, error) {
+	invalid := util.NewCustomError(int(util.ErrUnauthorizedCode), "Invalid API key")
+
+	prefix, secret, found := strings.Cut(strings.TrimPrefix(rawKey, apiKeyPrefix), "_")
+	if !found || !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, invalid
+	}
+
+	key, err := u.APIKeyRepo.FindByPrefix(ctx, prefix)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, invalid
+	}
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to find API key")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if subtle.ConstantTimeCompare([]byte(util.HashToken(secret)), []byte(key.SecretHash)) != 1 {
+		return nil, invalid
+	}
+
+	now := time.Now()
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "API key has expired")
+	}
+
+	if err := u.APIKeyRepo.Touch(ctx, key.ID, now, now.Add(-apiKeyTouchInterval)); err != nil {
+		u.Log.WithError(err).Warn("Failed to record API key use")
+	}
+
+	user := &key.User
+	user.Scopes = make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		user.Scopes = append(user.Scopes, scope.Scope)
+	}
+	return user, nil
+}
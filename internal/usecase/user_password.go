@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"go-todo-api/internal/util"
+	"net/url"
+	"time"
+
+	"github.com/gocraft/work"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	JobPasswordReset = "password_reset"
+
+	passwordResetTTL = 30 * time.Minute
+	// At most passwordResetLimit reset emails are sent to a user per
+	// passwordResetWindow, however often one is requested.
+	passwordResetLimit  = 3
+	passwordResetWindow = time.Hour
+)
+
+// ForgotPassword queues a password reset for the email and always succeeds.
+// The account is looked up by the job, so neither the response nor its
+// timing tells whether the address is registered.
+func (u *UserUsecase) ForgotPassword(ctx context.Context, request *domain.ForgotPasswordRequest) error {
+	if err := u.Outbox.Enqueue(ctx, JobPasswordReset, work.Q{"email": request.Email}); err != nil {
+		u.Log.WithError(err).Error("Failed to queue password reset")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return nil
+}
+
+// SendPasswordReset handles the password reset job published from
+// outboxID: it emails the account of email a single-use link, unless there
+// is no such account, it already received too many, or the job already ran.
+func (u *UserUsecase) SendPasswordReset(ctx context.Context, email, outboxID string) error {
+	return u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if first, err := u.Outbox.MarkProcessed(ctx, outboxID); err != nil || !first {
+			return err
+		}
+
+		user, err := u.UserRepo.FindByEmail(ctx, email)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.Log.Info("Password reset requested for an unknown email")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		sent, err := u.UserRepo.CountPasswordResetsSince(ctx, user.ID, now.Add(-passwordResetWindow))
+		if err != nil {
+			return err
+		}
+		if sent >= passwordResetLimit {
+			u.Log.Warnf("Password reset limit reached for user %d", user.ID)
+			return nil
+		}
+
+		token, err := util.NewOpaqueToken()
+		if err != nil {
+			return err
+		}
+
+		err = u.UserRepo.CreatePasswordReset(ctx, &entity.PasswordReset{
+			UserID:    user.ID,
+			TokenHash: util.HashToken(token),
+			ExpiresAt: now.Add(passwordResetTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		return u.Notifier.Send(ctx, user.ID, templates.KindPasswordReset, passwordResetEmailData{
+			URL:       u.AppURL + "/reset-password?token=" + url.QueryEscape(token),
+			ExpiresIn: int(passwordResetTTL.Minutes()),
+		})
+	})
+}
+
+// ResetPassword sets a new password with a reset token. Every other reset
+// link of the user stops working, and whoever else had access to the
+// account loses it, see revokeAccess.
+func (u *UserUsecase) ResetPassword(ctx context.Context, request *domain.ResetPasswordRequest) error {
+	invalid := util.NewCustomError(int(util.ErrBadRequestCode), "Invalid or expired reset token")
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		reset, err := u.UserRepo.FindPasswordReset(ctx, util.HashToken(request.Token))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to find password reset")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		now := time.Now()
+		if reset.UsedAt != nil || !reset.ExpiresAt.After(now) {
+			return invalid
+		}
+
+		user, err := u.UserRepo.FindByID(ctx, reset.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		password, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to generate bcrype hash")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		user.Password = string(password)
+
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to update user password")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if err := u.UserRepo.ConsumePasswordResets(ctx, user.ID, now); err != nil {
+			u.Log.WithError(err).Error("Failed to consume password resets")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		return u.revokeAccess(ctx, user.ID, now)
+	})
+	if err != nil {
+		return txError(err)
+	}
+
+	return nil
+}
+
+// revokeAccess signs userID out everywhere: sessions are revoked, API keys
+// deleted and pending two-factor logins dropped, so nothing obtained before
+// the account was recovered keeps working.
+func (u *UserUsecase) revokeAccess(ctx context.Context, userID uint, now time.Time) error {
+	if err := u.SessionRepo.RevokeAll(ctx, userID, now); err != nil {
+		u.Log.WithError(err).Error("Failed to revoke sessions")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if err := u.APIKeyRepo.DeleteByUserID(ctx, userID); err != nil {
+		u.Log.WithError(err).Error("Failed to revoke API keys")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if err := u.TwoFactorRepo.DeleteLoginChallenges(ctx, userID); err != nil {
+		u.Log.WithError(err).Error("Failed to drop login challenges")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return nil
+}
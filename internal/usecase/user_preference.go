@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"go-todo-api/internal/util"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const defaultDigestHour = 8
+
+func defaultPreference(userID uint) *entity.UserPreference {
+	return &entity.UserPreference{
+		UserID:          userID,
+		DigestFrequency: domain.DigestOff,
+		DigestHour:      defaultDigestHour,
+		DigestWeekday:   int(time.Monday),
+		Timezone:        "UTC",
+		Locale:          templates.DefaultLocale,
+	}
+}
+
+func (u *UserUsecase) findPreference(ctx context.Context, userID uint) (*entity.UserPreference, error) {
+	preference, err := u.UserRepo.FindPreference(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultPreference(userID), nil
+	}
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to find user preferences")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return preference, nil
+}
+
+func (u *UserUsecase) Preferences(ctx context.Context, request *domain.CurrentUserRequest) (*domain.UserPreferenceResponse, error) {
+	preference, err := u.findPreference(ctx, request.ID)
+	if err != nil {
+		return nil, err
+	}
+	return converter.UserPreferenceToResponse(preference), nil
+}
+
+func (u *UserUsecase) UpdatePreferences(ctx context.Context, request *domain.UserPreferenceUpdateRequest) (*domain.UserPreferenceResponse, error) {
+	if request.Timezone != nil {
+		if _, err := loadTimezone(*request.Timezone); err != nil {
+			return nil, util.NewCustomError(int(util.ErrBadRequestCode), err.Error())
+		}
+	}
+
+	if request.Locale != nil && !templates.IsLocale(*request.Locale) {
+		return nil, util.NewCustomError(int(util.ErrBadRequestCode), "Unsupported locale "+*request.Locale)
+	}
+
+	var preference *entity.UserPreference
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		preference, err = u.findPreference(ctx, request.UserID)
+		if err != nil {
+			return err
+		}
+
+		before := *preference
+		if request.DigestFrequency != nil {
+			preference.DigestFrequency = *request.DigestFrequency
+		}
+		if request.DigestHour != nil {
+			preference.DigestHour = *request.DigestHour
+		}
+		if request.DigestWeekday != nil {
+			preference.DigestWeekday = *request.DigestWeekday
+		}
+		if request.Timezone != nil {
+			preference.Timezone = *request.Timezone
+		}
+		if request.Locale != nil {
+			preference.Locale = *request.Locale
+		}
+
+		// A new schedule starts from now, so moving the send hour to one that
+		// has already passed today does not send a digest straight away.
+		if preference.DigestFrequency != before.DigestFrequency ||
+			preference.DigestHour != before.DigestHour ||
+			preference.DigestWeekday != before.DigestWeekday ||
+			preference.Timezone != before.Timezone {
+			now := time.Now()
+			preference.LastDigestAt = &now
+		}
+
+		if err := u.UserRepo.SavePreference(ctx, preference); err != nil {
+			u.Log.WithError(err).Error("Failed to save user preferences")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.UserPreferenceToResponse(preference), nil
+}
+
+// loadTimezone resolves an IANA timezone name. "Local" is rejected because it
+// depends on the server rather than the user.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("unknown timezone " + name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, errors.New("unknown timezone " + name)
+	}
+	return location, nil
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	JobSessionPrune = "session_prune"
+
+	maxUserAgentLength = 255
+)
+
+type SessionRepository interface {
+	Create(ctx context.Context, session *entity.Session) error
+	FindActive(ctx context.Context, id uuid.UUID, now time.Time) (*entity.Session, error)
+	FindActiveByUserID(ctx context.Context, userID uint, now time.Time) ([]entity.Session, error)
+	CreateRefreshToken(ctx context.Context, token *entity.RefreshToken) error
+	FindRefreshToken(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, id uint64, at time.Time) (bool, error)
+	Extend(ctx context.Context, id uint64, usedAt, expiresAt time.Time) error
+	Revoke(ctx context.Context, id uint64, at time.Time) error
+	RevokeByUUID(ctx context.Context, userID uint, id uuid.UUID, at time.Time) (bool, error)
+	RevokeAll(ctx context.Context, userID uint, at time.Time) error
+	RevokeOthers(ctx context.Context, userID uint, keep uuid.UUID, at time.Time) error
+	DeleteEndedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// startSession signs user in on a new device and issues its first tokens.
+func (u *UserUsecase) startSession(ctx context.Context, user *entity.User, userAgent, ipAddress string) (*domain.UserResponse, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := time.Now()
+	session := &entity.Session{
+		UUID:       uuid.New(),
+		UserID:     user.ID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		ExpiresAt:  now.Add(u.JwtService.RefreshTTL),
+		LastUsedAt: now,
+	}
+	if err := u.SessionRepo.Create(ctx, session); err != nil {
+		u.Log.WithError(err).Error("Failed to create session")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return u.issueTokens(ctx, user, session)
+}
+
+// issueTokens signs an access token for session together with a new refresh
+// token, of which only the hash is stored.
+func (u *UserUsecase) issueTokens(ctx context.Context, user *entity.User, session *entity.Session) (*domain.UserResponse, error) {
+	refreshToken, err := util.NewOpaqueToken()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate refresh token")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	err = u.SessionRepo.CreateRefreshToken(ctx, &entity.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: util.HashToken(refreshToken),
+	})
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to store refresh token")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	token, expiresAt, err := u.JwtService.CreateToken(user, session)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to create jwt token")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return converter.UserToResponseWithToken(user, token, expiresAt, refreshToken), nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Each refresh token
+// works once; presenting a used one means it was copied, so the whole
+// session is revoked and every holder has to sign in again.
+func (u *UserUsecase) Refresh(ctx context.Context, request *domain.RefreshTokenRequest) (*domain.UserResponse, error) {
+	var (
+		response *domain.UserResponse
+		reused   bool
+	)
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		token, err := u.SessionRepo.FindRefreshToken(ctx, util.HashToken(request.RefreshToken))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return util.NewCustomError(int(util.ErrUnauthorizedCode), "Invalid refresh token")
+			}
+			u.Log.WithError(err).Error("Failed to find refresh token")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		session := &token.Session
+		now := time.Now()
+		if session.RevokedAt != nil || !session.ExpiresAt.After(now) {
+			return util.NewCustomError(int(util.ErrUnauthorizedCode), "Session has ended, please log in again")
+		}
+
+		claimed, err := u.SessionRepo.MarkRefreshTokenUsed(ctx, token.ID, now)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to mark refresh token used")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if !claimed {
+			u.Log.Warnf("Refresh token reused for session %s, revoking it", session.UUID)
+			reused = true
+			// Returning nil commits the revocation.
+			return u.SessionRepo.Revoke(ctx, session.ID, now)
+		}
+
+		user, err := u.UserRepo.FindByID(ctx, session.UserID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return util.NewCustomError(int(util.ErrUnauthorizedCode), "User not found")
+			}
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		session.LastUsedAt = now
+		session.ExpiresAt = now.Add(u.JwtService.RefreshTTL)
+		if err := u.SessionRepo.Extend(ctx, session.ID, session.LastUsedAt, session.ExpiresAt); err != nil {
+			u.Log.WithError(err).Error("Failed to extend session")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		response, err = u.issueTokens(ctx, user, session)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+	if reused {
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Refresh token was already used, the session has been revoked")
+	}
+
+	return response, nil
+}
+
+// Authenticate validates an access token and returns its user and session,
+// provided the session is still active.
+func (u *UserUsecase) Authenticate(ctx context.Context, token string) (*entity.User, *entity.Session, error) {
+	claims, err := u.JwtService.ValidateToken(token)
+	if err != nil {
+		return nil, nil, util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
+	}
+
+	session, err := u.SessionRepo.FindActive(ctx, claims.SessionID, time.Now())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Session expired or revoked")
+		}
+		u.Log.WithError(err).Error("Failed to find session")
+		return nil, nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if session.UserID != claims.UserID {
+		return nil, nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Token invalid or missing")
+	}
+
+	return &session.User, session, nil
+}
+
+func (u *UserUsecase) Sessions(ctx context.Context, request *domain.SessionListRequest) ([]*domain.SessionResponse, error) {
+	sessions, err := u.SessionRepo.FindActiveByUserID(ctx, request.ID, time.Now())
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to find sessions")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	responses := make([]*domain.SessionResponse, 0, len(sessions))
+	for i := range sessions {
+		responses = append(responses, converter.SessionToResponse(&sessions[i], request.SessionID))
+	}
+
+	return responses, nil
+}
+
+func (u *UserUsecase) RevokeSession(ctx context.Context, request *domain.SessionRevokeRequest) error {
+	revoked, err := u.SessionRepo.RevokeByUUID(ctx, request.ID, request.UUID, time.Now())
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to revoke session")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if !revoked {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Session not found")
+	}
+
+	return nil
+}
+
+// PruneSessions deletes sessions, and login challenges and single sign-on
+// states, that ended longer than retention ago.
+func (u *UserUsecase) PruneSessions(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().Add(-retention)
+	if _, err := u.TwoFactorRepo.DeleteLoginChallengesBefore(ctx, before); err != nil {
+		return 0, err
+	}
+	if _, err := u.SSORepo.DeleteStatesBefore(ctx, before); err != nil {
+		return 0, err
+	}
+	return u.SessionRepo.DeleteEndedBefore(ctx, before)
+}
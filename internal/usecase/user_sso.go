@@ -0,0 +1,335 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/oidc"
+	"go-todo-api/internal/util"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	ssoStateTTL = 10 * time.Minute
+	// A name taken by another user gets a random suffix; after
+	// ssoNameAttempts tries, provisioning gives up.
+	ssoNameAttempts   = 5
+	maxUserNameLength = 100
+)
+
+type SSORepository interface {
+	CreateState(ctx context.Context, state *entity.SSOState) error
+	TakeState(ctx context.Context, stateHash string) (*entity.SSOState, error)
+	DeleteStatesBefore(ctx context.Context, before time.Time) (int64, error)
+	FindIdentity(ctx context.Context, issuer, subject string) (*entity.UserIdentity, error)
+	SaveIdentity(ctx context.Context, identity *entity.UserIdentity) error
+}
+
+func (u *UserUsecase) ssoConfigured() error {
+	if u.SSO == nil {
+		return util.NewCustomError(int(util.ErrNotFoundCode), "Single sign-on is not configured")
+	}
+	return nil
+}
+
+// StartSSO begins a sign-in with the identity provider, using the
+// authorization code flow with PKCE. The state, nonce and code verifier are
+// kept until the provider sends the user back to CompleteSSO. The hash of
+// the state is returned for the caller to keep in the browser.
+func (u *UserUsecase) StartSSO(ctx context.Context) (*domain.SSOStartResponse, error) {
+	if err := u.ssoConfigured(); err != nil {
+		return nil, err
+	}
+
+	var secrets [3]string
+	for i := range secrets {
+		secret, err := util.NewOpaqueToken()
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to generate sign-in state")
+			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		secrets[i] = secret
+	}
+	state, nonce, verifier := secrets[0], secrets[1], secrets[2]
+
+	authURL, err := u.SSO.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to reach identity provider")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), "Identity provider is unavailable")
+	}
+
+	stateHash := util.HashToken(state)
+	err = u.SSORepo.CreateState(ctx, &entity.SSOState{
+		StateHash:    stateHash,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(ssoStateTTL),
+	})
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to store sign-in state")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return &domain.SSOStartResponse{URL: authURL, StateHash: stateHash}, nil
+}
+
+// CompleteSSO redeems the code the identity provider sent the user back
+// with. The identity is matched to a user by its subject, or else linked to
+// the user with the same email, or else provisioned as a new user; the
+// latter two only when the provider verified the email. Users with
+// two-factor authentication still get a challenge.
+//
+// The state is only accepted together with the hash StartSSO returned for
+// it, so a sign-in cannot be completed in a browser that did not start it.
+func (u *UserUsecase) CompleteSSO(ctx context.Context, request *domain.SSOCallbackRequest) (*domain.UserResponse, error) {
+	if err := u.ssoConfigured(); err != nil {
+		return nil, err
+	}
+
+	stateHash := util.HashToken(request.State)
+	if subtle.ConstantTimeCompare([]byte(request.StateHash), []byte(stateHash)) != 1 {
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Sign-in was started in another browser, please start again")
+	}
+
+	// The state is spent whether or not the sign-in succeeds.
+	state, err := u.SSORepo.TakeState(ctx, stateHash)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		u.Log.WithError(err).Error("Failed to find sign-in state")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if state == nil || !state.ExpiresAt.After(time.Now()) {
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Invalid or expired sign-in, please start again")
+	}
+
+	identity, err := u.SSO.Exchange(ctx, request.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to redeem authorization code")
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Sign-in with the identity provider failed")
+	}
+
+	var response *domain.UserResponse
+
+	err = u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.ssoUser(ctx, identity)
+		if err != nil {
+			return err
+		}
+
+		if user.TOTPEnabledAt != nil {
+			response, err = u.startChallenge(ctx, user)
+			return err
+		}
+
+		response, err = u.startSession(ctx, user, request.UserAgent, request.IPAddress)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return response, nil
+}
+
+// ssoUser finds, links or provisions the user of identity and brings their
+// role in line with the groups of identity.
+func (u *UserUsecase) ssoUser(ctx context.Context, identity *oidc.Identity) (*entity.User, error) {
+	now := time.Now()
+	role, mapped := u.ssoRole(identity.Groups)
+
+	link, err := u.SSORepo.FindIdentity(ctx, identity.Issuer, identity.Subject)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		u.Log.WithError(err).Error("Failed to find identity")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	var user *entity.User
+	if link != nil {
+		user, err = u.UserRepo.FindByID(ctx, link.UserID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "User not found")
+		}
+	} else {
+		if identity.Email == "" || !identity.EmailVerified {
+			return nil, util.NewCustomError(int(util.ErrForbiddenCode), "The identity provider has not verified the email address of this account")
+		}
+
+		user, err = u.UserRepo.FindByEmail(ctx, identity.Email)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user, err = u.provisionSSOUser(ctx, identity, role, now)
+			if err != nil {
+				return nil, err
+			}
+		case err != nil:
+			u.Log.WithError(err).Error("Failed to found user")
+			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		default:
+			if err := u.claimUnverifiedUser(ctx, user, now); err != nil {
+				return nil, err
+			}
+		}
+		link = &entity.UserIdentity{UserID: user.ID, Issuer: identity.Issuer, Subject: identity.Subject}
+	}
+
+	link.Email = identity.Email
+	link.LastLoginAt = &now
+	if err := u.SSORepo.SaveIdentity(ctx, link); err != nil {
+		u.Log.WithError(err).Error("Failed to save identity")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	if mapped && user.Role != role {
+		u.Log.Infof("Changing role of user %d from %s to %s after single sign-on", user.ID, user.Role, role)
+		user.Role = role
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to update user role")
+			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+	}
+
+	return user, nil
+}
+
+// claimUnverifiedUser is called when an identity with a verified email is
+// linked to user. Whoever registered an address they never verified may not
+// own it, so everything they set up is dropped in favour of the identity:
+// their password, pending email change, second factor and custom role, as
+// well as any access they had.
+func (u *UserUsecase) claimUnverifiedUser(ctx context.Context, user *entity.User, now time.Time) error {
+	if user.VerifiedAt != nil {
+		return nil
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate password")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	user.Password = password
+	user.VerifiedAt = &now
+	user.PendingEmail = nil
+	user.VerificationSentAt = nil
+	user.TOTPSecret = nil
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	user.RoleID = nil
+	if err := u.UserRepo.Update(ctx, user); err != nil {
+		u.Log.WithError(err).Error("Failed to update user")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	if err := u.TwoFactorRepo.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+		u.Log.WithError(err).Error("Failed to delete recovery codes")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if err := u.UserRepo.ConsumePasswordResets(ctx, user.ID, now); err != nil {
+		u.Log.WithError(err).Error("Failed to consume password resets")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return u.revokeAccess(ctx, user.ID, now)
+}
+
+// provisionSSOUser creates a user for identity on their first sign-in. It
+// gets an unusable random password, which a password reset can replace.
+func (u *UserUsecase) provisionSSOUser(ctx context.Context, identity *oidc.Identity, role string, now time.Time) (*entity.User, error) {
+	name, err := u.availableUserName(ctx, ssoUserName(identity))
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := randomPassword()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate password")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	user := &entity.User{
+		Name:       name,
+		Email:      identity.Email,
+		Password:   password,
+		Role:       role,
+		VerifiedAt: &now,
+	}
+	if user.Role == "" {
+		user.Role = entity.RoleUser
+	}
+	if err := u.UserRepo.Create(ctx, user); err != nil {
+		u.Log.WithError(err).Error("Failed to create user")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	u.Log.Infof("Provisioned user %d from single sign-on", user.ID)
+	return user, nil
+}
+
+// ssoUserName is the display name of identity, or else the local part of
+// its email.
+func ssoUserName(identity *oidc.Identity) string {
+	name := strings.TrimSpace(identity.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+	// Leave room for the suffix of availableUserName.
+	if runes := []rune(name); len(runes) > maxUserNameLength-9 {
+		name = string(runes[:maxUserNameLength-9])
+	}
+	return name
+}
+
+// availableUserName returns name, or name with a random suffix when another
+// user has it, since names are unique.
+func (u *UserUsecase) availableUserName(ctx context.Context, name string) (string, error) {
+	candidate := name
+	for range ssoNameAttempts {
+		count, err := u.UserRepo.CountByEmailOrName(ctx, &entity.User{Name: candidate})
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to check user name")
+			return "", util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+
+		suffix, err := util.NewKeyPrefix()
+		if err != nil {
+			return "", util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		candidate = name + "-" + suffix
+	}
+	return "", util.NewCustomError(int(util.ErrConflictCode), "No user name available for "+name)
+}
+
+// ssoRole maps groups onto a built-in role, the highest any group grants,
+// and reports false when no mapping is configured.
+func (u *UserUsecase) ssoRole(groups []string) (string, bool) {
+	if len(u.SSO.Config.GroupRoles) == 0 {
+		return "", false
+	}
+
+	role := entity.RoleUser
+	for _, group := range groups {
+		if u.SSO.Config.GroupRoles[group] == entity.RoleAdmin {
+			role = entity.RoleAdmin
+		}
+	}
+	return role, true
+}
+
+func randomPassword() (string, error) {
+	secret, err := util.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
@@ -0,0 +1,385 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/util"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	loginChallengeTTL = 5 * time.Minute
+	// A challenge is spent after loginChallengeAttempts wrong codes, so codes
+	// cannot be guessed without the password.
+	loginChallengeAttempts = 5
+	// Across challenges, a user's codes are refused for a while once
+	// twoFactorLockoutAttempts wrong ones were sent within
+	// twoFactorLockoutWindow, so new challenges do not reset the count.
+	twoFactorLockoutAttempts = 10
+	twoFactorLockoutWindow   = 15 * time.Minute
+	recoveryCodeCount        = 10
+)
+
+type TwoFactorRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, userID uint, codeHashes []string) error
+	DeleteRecoveryCodes(ctx context.Context, userID uint) error
+	UseRecoveryCode(ctx context.Context, userID uint, codeHash string, at time.Time) (bool, error)
+	UseTOTPStep(ctx context.Context, userID uint, step int64) (bool, error)
+	CreateLoginChallenge(ctx context.Context, challenge *entity.LoginChallenge) error
+	FindLoginChallenge(ctx context.Context, tokenHash string) (*entity.LoginChallenge, error)
+	UpdateLoginChallenge(ctx context.Context, challenge *entity.LoginChallenge) error
+	DeleteLoginChallenges(ctx context.Context, userID uint) error
+	DeleteLoginChallengesBefore(ctx context.Context, before time.Time) (int64, error)
+	CountFailedAttemptsSince(ctx context.Context, userID uint, since time.Time) (int64, error)
+}
+
+func invalidTwoFactorCode() error {
+	return util.NewCustomError(int(util.ErrBadRequestCode), "Invalid two-factor code")
+}
+
+// startChallenge answers the password step of a login by a user with
+// two-factor authentication: no tokens yet, only a challenge to exchange
+// together with a code.
+func (u *UserUsecase) startChallenge(ctx context.Context, user *entity.User) (*domain.UserResponse, error) {
+	token, err := util.NewOpaqueToken()
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to generate challenge token")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	expiresAt := time.Now().Add(loginChallengeTTL)
+	err = u.TwoFactorRepo.CreateLoginChallenge(ctx, &entity.LoginChallenge{
+		UserID:    user.ID,
+		TokenHash: util.HashToken(token),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to create login challenge")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return &domain.UserResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+		ExpiresAt:         &expiresAt,
+	}, nil
+}
+
+// completeChallenge is the second step of a login with two-factor
+// authentication. Wrong codes are counted even though the login fails, and
+// a user with too many of them is locked out of the step for a while.
+func (u *UserUsecase) completeChallenge(ctx context.Context, request *domain.LoginUserRequest) (*domain.UserResponse, error) {
+	var (
+		response *domain.UserResponse
+		rejected bool
+	)
+	invalid := util.NewCustomError(int(util.ErrUnauthorizedCode), "Invalid or expired login challenge")
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		challenge, err := u.TwoFactorRepo.FindLoginChallenge(ctx, util.HashToken(request.ChallengeToken))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to find login challenge")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		now := time.Now()
+		if challenge.UsedAt != nil || !challenge.ExpiresAt.After(now) || challenge.Attempts >= loginChallengeAttempts {
+			return invalid
+		}
+
+		user, err := u.UserRepo.FindByID(ctx, challenge.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		failed, err := u.TwoFactorRepo.CountFailedAttemptsSince(ctx, user.ID, now.Add(-twoFactorLockoutWindow))
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to count two-factor attempts")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if failed >= twoFactorLockoutAttempts {
+			u.Log.Warnf("Two-factor attempts of user %d are locked out", user.ID)
+			return util.NewCustomError(int(util.ErrTooManyRequestsCode), "Too many invalid two-factor codes, try again later")
+		}
+
+		valid, err := u.verifySecondFactor(ctx, user, request.Code, now)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			rejected = true
+			challenge.Attempts++
+		} else {
+			challenge.UsedAt = &now
+		}
+		if err := u.TwoFactorRepo.UpdateLoginChallenge(ctx, challenge); err != nil {
+			u.Log.WithError(err).Error("Failed to update login challenge")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if rejected {
+			// Returning nil commits the attempt.
+			return nil
+		}
+
+		response, err = u.startSession(ctx, user, request.UserAgent, request.IPAddress)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+	if rejected {
+		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), "Invalid two-factor code")
+	}
+
+	return response, nil
+}
+
+// verifySecondFactor accepts either a current TOTP code, which cannot be
+// used again, or an unused recovery code, which is used up.
+func (u *UserUsecase) verifySecondFactor(ctx context.Context, user *entity.User, code string, now time.Time) (bool, error) {
+	if user.TOTPEnabledAt == nil {
+		return false, nil
+	}
+
+	valid, err := u.verifyTOTP(ctx, user, code, now)
+	if err != nil || valid {
+		return valid, err
+	}
+
+	used, err := u.TwoFactorRepo.UseRecoveryCode(ctx, user.ID, util.HashToken(util.NormalizeRecoveryCode(code)), now)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to use recovery code")
+		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return used, nil
+}
+
+// verifyTOTP checks code against the secret of user, enrolled or not, and
+// records its time step. The step is only recorded if it is later than the
+// stored one, so of two logins racing with the same code only one passes.
+func (u *UserUsecase) verifyTOTP(ctx context.Context, user *entity.User, code string, now time.Time) (bool, error) {
+	if user.TOTPSecret == nil {
+		return false, nil
+	}
+
+	secret, err := u.TwoFactor.Box.Open(*user.TOTPSecret)
+	if err != nil {
+		u.Log.WithError(err).Errorf("Failed to decrypt TOTP secret of user %d", user.ID)
+		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	step, valid := util.ValidateTOTP(secret, code, now, user.TOTPLastStep)
+	if !valid {
+		return false, nil
+	}
+
+	used, err := u.TwoFactorRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to record TOTP step")
+		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if !used {
+		return false, nil
+	}
+
+	user.TOTPLastStep = step
+	return true, nil
+}
+
+// issueRecoveryCodes replaces the recovery codes of user and returns the new
+// ones, which are only ever shown this once.
+func (u *UserUsecase) issueRecoveryCodes(ctx context.Context, user *entity.User) (*domain.RecoveryCodesResponse, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := util.NewRecoveryCode()
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to generate recovery code")
+			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, util.HashToken(util.NormalizeRecoveryCode(code)))
+	}
+
+	if err := u.TwoFactorRepo.ReplaceRecoveryCodes(ctx, user.ID, hashes); err != nil {
+		u.Log.WithError(err).Error("Failed to store recovery codes")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	return &domain.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// EnrollTwoFactor starts enrollment with a new secret. Two-factor
+// authentication is only turned on by ConfirmTwoFactor, once the user proves
+// their app generates codes for it.
+func (u *UserUsecase) EnrollTwoFactor(ctx context.Context, request *domain.TwoFactorEnrollRequest) (*domain.TwoFactorEnrollResponse, error) {
+	var response *domain.TwoFactorEnrollResponse
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.UserRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if user.TOTPEnabledAt != nil {
+			return util.NewCustomError(int(util.ErrConflictCode), "Two-factor authentication is already enabled")
+		}
+
+		secret, err := util.NewTOTPSecret()
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to generate TOTP secret")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		sealed, err := u.TwoFactor.Box.Seal(secret)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to encrypt TOTP secret")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		user.TOTPSecret = &sealed
+		user.TOTPLastStep = 0
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to update user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		response = &domain.TwoFactorEnrollResponse{
+			Secret: secret,
+			URI:    util.TOTPURI(secret, u.TwoFactor.Issuer, user.Email),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return response, nil
+}
+
+// ConfirmTwoFactor turns two-factor authentication on with a code from the
+// enrolled app and returns the first recovery codes.
+func (u *UserUsecase) ConfirmTwoFactor(ctx context.Context, request *domain.TwoFactorCodeRequest) (*domain.RecoveryCodesResponse, error) {
+	var response *domain.RecoveryCodesResponse
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.UserRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		if user.TOTPEnabledAt != nil {
+			return util.NewCustomError(int(util.ErrConflictCode), "Two-factor authentication is already enabled")
+		}
+		if user.TOTPSecret == nil {
+			return util.NewCustomError(int(util.ErrConflictCode), "Two-factor enrollment has not been started")
+		}
+
+		now := time.Now()
+		valid, err := u.verifyTOTP(ctx, user, request.Code, now)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			return invalidTwoFactorCode()
+		}
+
+		user.TOTPEnabledAt = &now
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to enable two-factor authentication")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		response, err = u.issueRecoveryCodes(ctx, user)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return response, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes, for instance after
+// the old ones were lost or mostly used.
+func (u *UserUsecase) RegenerateRecoveryCodes(ctx context.Context, request *domain.TwoFactorCodeRequest) (*domain.RecoveryCodesResponse, error) {
+	var response *domain.RecoveryCodesResponse
+
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.enrolledUser(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		response, err = u.issueRecoveryCodes(ctx, user)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return response, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off. Users whose role
+// requires it can still do so, but are refused anything that needs a
+// permission until they enroll again.
+func (u *UserUsecase) DisableTwoFactor(ctx context.Context, request *domain.TwoFactorCodeRequest) error {
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.enrolledUser(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		user.TOTPSecret = nil
+		user.TOTPEnabledAt = nil
+		user.TOTPLastStep = 0
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to disable two-factor authentication")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		if err := u.TwoFactorRepo.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+			u.Log.WithError(err).Error("Failed to delete recovery codes")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return txError(err)
+	}
+
+	return nil
+}
+
+// enrolledUser finds the user of request, who must have two-factor
+// authentication enabled and have sent a valid code.
+func (u *UserUsecase) enrolledUser(ctx context.Context, request *domain.TwoFactorCodeRequest) (*entity.User, error) {
+	user, err := u.UserRepo.FindByID(ctx, request.ID)
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to found user")
+		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if user.TOTPEnabledAt == nil {
+		return nil, util.NewCustomError(int(util.ErrConflictCode), "Two-factor authentication is not enabled")
+	}
+
+	valid, err := u.verifySecondFactor(ctx, user, request.Code, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, invalidTwoFactorCode()
+	}
+	return user, nil
+}
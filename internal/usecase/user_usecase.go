@@ -6,11 +6,12 @@ import (
 	"go-todo-api/domain/converter"
 	"go-todo-api/internal/config"
 	"go-todo-api/internal/entity"
+	"go-todo-api/internal/oidc"
 	"go-todo-api/internal/util"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 )
 
 type UserRepository interface {
@@ -21,94 +22,122 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id any) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
 	Delete(ctx context.Context, user *entity.User) error
+	FindPreference(ctx context.Context, userID uint) (*entity.UserPreference, error)
+	SavePreference(ctx context.Context, preference *entity.UserPreference) error
+	FindByEmail(ctx context.Context, email string) (*entity.User, error)
+	CreatePasswordReset(ctx context.Context, reset *entity.PasswordReset) error
+	CountPasswordResetsSince(ctx context.Context, userID uint, since time.Time) (int64, error)
+	FindPasswordReset(ctx context.Context, tokenHash string) (*entity.PasswordReset, error)
+	ConsumePasswordResets(ctx context.Context, userID uint, at time.Time) error
 }
 
 type UserUsecase struct {
-	DB         *gorm.DB
-	Log        *logrus.Logger
-	UserRepo   UserRepository
-	JwtService *config.JwtConfig
+	TxManager     TxManager
+	Log           *logrus.Logger
+	UserRepo      UserRepository
+	SessionRepo   SessionRepository
+	TwoFactorRepo TwoFactorRepository
+	APIKeyRepo    APIKeyRepository
+	SSORepo       SSORepository
+	JwtService    *config.JwtConfig
+	TwoFactor     *config.TwoFactorConfig
+	SSO           *oidc.Provider
+	Outbox        *OutboxUsecase
+	Notifier      *NotificationUsecase
+	AppURL        string
+	Verifier      *util.TokenSigner
 }
 
-func NewUserUsecase(u UserRepository, db *gorm.DB, logger *logrus.Logger, jwtService *config.JwtConfig) *UserUsecase {
+func NewUserUsecase(u UserRepository, sessionRepo SessionRepository, twoFactorRepo TwoFactorRepository, apiKeyRepo APIKeyRepository, ssoRepo SSORepository, txManager TxManager, logger *logrus.Logger, jwtService *config.JwtConfig, twoFactor *config.TwoFactorConfig, sso *oidc.Provider, outbox *OutboxUsecase, notifier *NotificationUsecase, appURL string, verifier *util.TokenSigner) *UserUsecase {
 	return &UserUsecase{
-		UserRepo:   u,
-		Log:        logger,
-		DB:         db,
-		JwtService: jwtService,
+		UserRepo:      u,
+		SessionRepo:   sessionRepo,
+		TwoFactorRepo: twoFactorRepo,
+		APIKeyRepo:    apiKeyRepo,
+		SSORepo:       ssoRepo,
+		Log:           logger,
+		TxManager:     txManager,
+		JwtService:    jwtService,
+		TwoFactor:     twoFactor,
+		SSO:           sso,
+		Outbox:        outbox,
+		Notifier:      notifier,
+		AppURL:        appURL,
+		Verifier:      verifier,
 	}
 }
 
 func (u *UserUsecase) Create(ctx context.Context, request *domain.RegisterUserRequest) (*domain.UserResponse, error) {
-	tx := u.DB.WithContext(ctx).Begin()
-
-	existingUser, _ := u.UserRepo.FindByEmailOrName(tx.Statement.Context, request.Email, request.Name)
-	if existingUser != nil {
-		u.Log.Warnf("Error checking for existing user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), "User with email or name already exists")
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
-	if err != nil {
-		u.Log.WithError(err).Error("Failed to generate bcrype hash")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
-
 	userPayload := &entity.User{
-		Name:     request.Name,
-		Email:    request.Email,
-		Password: string(hashedPassword),
+		Name:  request.Name,
+		Email: request.Email,
 	}
 
-	if err := u.UserRepo.Create(tx.Statement.Context, userPayload); err != nil {
-		u.Log.WithError(err).Error("Failed to create user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		existingUser, _ := u.UserRepo.FindByEmailOrName(ctx, request.Email, request.Name)
+		if existingUser != nil {
+			u.Log.Warnf("Error checking for existing user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), "User with email or name already exists")
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		u.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to generate bcrype hash")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		userPayload.Password = string(hashedPassword)
+		now := time.Now()
+		userPayload.VerificationSentAt = &now
+
+		if err := u.UserRepo.Create(ctx, userPayload); err != nil {
+			u.Log.WithError(err).Error("Failed to create user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return u.sendVerification(ctx, userPayload, userPayload.Email)
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
 	return converter.UserToResponse(userPayload), nil
 }
 
+// Login signs a user in with their password. Users with two-factor
+// authentication get a challenge instead, which they send back to Login
+// together with a code from their app or a recovery code.
 func (u *UserUsecase) Login(ctx context.Context, request *domain.LoginUserRequest) (*domain.UserResponse, error) {
-	tx := u.DB.WithContext(ctx).Begin()
-
-	user, err := u.UserRepo.FindByEmailOrName(tx.Statement.Context, request.Email, "")
-	if err != nil {
-		u.Log.WithError(err).Error("Failed to found user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	if request.ChallengeToken != "" {
+		return u.completeChallenge(ctx, request)
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
-		u.Log.WithError(err).Error("Failed to compare user password with bcrype hash")
-		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
-	}
+	var response *domain.UserResponse
 
-	token, err := u.JwtService.CreateToken(user)
-	if err != nil {
-		u.Log.WithError(err).Error("Failed to create jwt token")
-		return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
-	}
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.UserRepo.FindByEmailOrName(ctx, request.Email, "")
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
 
-	user.Token = token
-	if err := u.UserRepo.Update(tx.Statement.Context, user); err != nil {
-		u.Log.WithError(err).Error("Failed to update user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.Password)); err != nil {
+			u.Log.WithError(err).Error("Failed to compare user password with bcrype hash")
+			return util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		u.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		if user.TOTPEnabledAt != nil {
+			response, err = u.startChallenge(ctx, user)
+			return err
+		}
+
+		response, err = u.startSession(ctx, user, request.UserAgent, request.IPAddress)
+		return err
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
-	return converter.UserToResponseWithToken(user, token), nil
+	return response, nil
 }
-
 func (u *UserUsecase) GetUserID(ctx context.Context, request *domain.GetUserId) (*entity.User, error) {
 	user, err := u.UserRepo.FindByID(ctx, request.ID)
 	if err != nil {
@@ -119,30 +148,16 @@ func (u *UserUsecase) GetUserID(ctx context.Context, request *domain.GetUserId)
 	return user, nil
 }
 
+// Logout revokes the session the request was made from; the user's other
+// devices stay signed in.
 func (u *UserUsecase) Logout(ctx context.Context, request *domain.LogoutUserRequest) (bool, error) {
-	tx := u.DB.WithContext(ctx).Begin()
-
-	user, err := u.UserRepo.FindByID(tx.Statement.Context, request.ID)
-	if err != nil {
-		u.Log.WithError(err).Error("Failed to found user")
-		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
-
-	user.Token = ""
-	if err := u.UserRepo.Update(tx.Statement.Context, user); err != nil {
-		u.Log.WithError(err).Error("Failed to update token user")
-		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
-
-	if err := tx.Commit().Error; err != nil {
-		u.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
+	if _, err := u.SessionRepo.RevokeByUUID(ctx, request.ID, request.SessionID, time.Now()); err != nil {
+		u.Log.WithError(err).Error("Failed to revoke session")
 		return false, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
 	}
 
 	return true, nil
 }
-
 func (u *UserUsecase) Current(ctx context.Context, request *domain.CurrentUserRequest) (*domain.UserResponse, error) {
 	user, err := u.UserRepo.FindByID(ctx, request.ID)
 	if err != nil {
@@ -154,57 +169,65 @@ func (u *UserUsecase) Current(ctx context.Context, request *domain.CurrentUserRe
 }
 
 func (u *UserUsecase) Update(ctx context.Context, request *domain.UserUpdateRequest) (*domain.UserResponse, error) {
-	tx := u.DB.WithContext(ctx).Begin()
-
-	user, err := u.UserRepo.FindByID(tx.Statement.Context, request.ID)
-	if err != nil {
-		u.Log.WithError(err).Error("Failed to found user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
-
-	if user.Name == request.Name || user.Email == request.Email {
-		u.Log.Warn("Request name or email same before")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), "Request name or email same before")
-	}
+	var user *entity.User
 
-	if request.OldPassword != "" && request.NewPassword != "" {
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.OldPassword)); err != nil {
-			u.Log.WithError(err).Error("Failed to compare user password with bcrype hash")
-			return nil, util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.UserRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
 		}
 
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.NewPassword)); err == nil {
-			u.Log.Warn("New password cannot be the same as the old password")
-			return nil, util.NewCustomError(int(util.ErrBadRequestCode), "New password cannot be the same as the old password")
+		if user.Name == request.Name || user.Email == request.Email {
+			u.Log.Warn("Request name or email same before")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), "Request name or email same before")
 		}
-	}
 
-	if request.Name != "" {
-		user.Name = request.Name
-	}
+		if request.OldPassword != "" && request.NewPassword != "" {
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.OldPassword)); err != nil {
+				u.Log.WithError(err).Error("Failed to compare user password with bcrype hash")
+				return util.NewCustomError(int(util.ErrUnauthorizedCode), err.Error())
+			}
 
-	if request.Email != "" {
-		user.Email = request.Email
-	}
+			if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(request.NewPassword)); err == nil {
+				u.Log.Warn("New password cannot be the same as the old password")
+				return util.NewCustomError(int(util.ErrBadRequestCode), "New password cannot be the same as the old password")
+			}
+		}
 
-	if request.NewPassword != "" {
-		password, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
-		if err != nil {
-			u.Log.Warnf("Failed to generate bcrype hash : %+v", err)
-			return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		if request.Name != "" {
+			user.Name = request.Name
 		}
-		user.Password = string(password)
-	}
 
-	if err := u.UserRepo.Update(tx.Statement.Context, user); err != nil {
-		u.Log.WithError(err).Error("Failed to update token user")
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
-	}
+		if request.Email != "" {
+			if err := u.requestEmailChange(ctx, user, request.Email); err != nil {
+				return err
+			}
+		}
 
-	if err := tx.Commit().Error; err != nil {
-		u.Log.WithError(err).Error("Failed to commit transaction")
-		tx.Rollback()
-		return nil, util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		if request.NewPassword != "" {
+			password, err := bcrypt.GenerateFromPassword([]byte(request.NewPassword), bcrypt.DefaultCost)
+			if err != nil {
+				u.Log.Warnf("Failed to generate bcrype hash : %+v", err)
+				return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			}
+			user.Password = string(password)
+
+			if err := u.SessionRepo.RevokeOthers(ctx, user.ID, request.SessionID, time.Now()); err != nil {
+				u.Log.WithError(err).Error("Failed to revoke other sessions")
+				return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+			}
+		}
+
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to update token user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
 	}
 
 	return converter.UserToResponse(user), nil
@@ -0,0 +1,170 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"go-todo-api/domain"
+	"go-todo-api/domain/converter"
+	"go-todo-api/internal/entity"
+	"go-todo-api/internal/templates"
+	"go-todo-api/internal/util"
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	emailVerificationTTL = 24 * time.Hour
+	// A verification email is resent at most once per
+	// verificationResendInterval.
+	verificationResendInterval = time.Minute
+)
+
+// emailVerificationClaims is signed into verification links. A link only
+// verifies the address it was sent to, so links to an address the user has
+// since replaced stop working.
+type emailVerificationClaims struct {
+	UserID uint   `json:"uid"`
+	Email  string `json:"email"`
+}
+
+// sendVerification emails a verification link for email, which is either
+// the user's email or the one they are changing to.
+func (u *UserUsecase) sendVerification(ctx context.Context, user *entity.User, email string) error {
+	token, err := u.Verifier.Sign(emailVerificationClaims{UserID: user.ID, Email: email}, time.Now().Add(emailVerificationTTL))
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to sign verification token")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+
+	err = u.Notifier.SendTo(ctx, user.ID, email, templates.KindEmailVerification, emailVerificationEmailData{
+		URL:       u.AppURL + "/verify-email?token=" + url.QueryEscape(token),
+		Email:     email,
+		ExpiresIn: int(emailVerificationTTL.Hours()),
+	})
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to queue verification email")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	return nil
+}
+
+// requestEmailChange keeps email pending and sends it a verification link;
+// the user's email only changes once the link is followed.
+func (u *UserUsecase) requestEmailChange(ctx context.Context, user *entity.User, email string) error {
+	if err := u.ensureEmailAvailable(ctx, user, email); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.PendingEmail = &email
+	user.VerificationSentAt = &now
+	return u.sendVerification(ctx, user, email)
+}
+
+func (u *UserUsecase) ensureEmailAvailable(ctx context.Context, user *entity.User, email string) error {
+	existing, err := u.UserRepo.FindByEmail(ctx, email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		u.Log.WithError(err).Error("Failed to find user by email")
+		return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+	}
+	if existing.ID != user.ID {
+		return util.NewCustomError(int(util.ErrConflictCode), "Email is already in use")
+	}
+	return nil
+}
+
+// VerifyEmail follows a verification link. It confirms the user's email, or
+// replaces it with the pending one the link was sent to. Following a link
+// for an address that is already verified succeeds again.
+func (u *UserUsecase) VerifyEmail(ctx context.Context, request *domain.VerifyEmailRequest) (*domain.UserResponse, error) {
+	invalid := util.NewCustomError(int(util.ErrBadRequestCode), "Invalid or expired verification token")
+
+	var claims emailVerificationClaims
+	if err := u.Verifier.Verify(request.Token, &claims); err != nil {
+		return nil, invalid
+	}
+
+	var user *entity.User
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = u.UserRepo.FindByID(ctx, claims.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid
+		}
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		now := time.Now()
+		switch {
+		case user.PendingEmail != nil && strings.EqualFold(*user.PendingEmail, claims.Email):
+			if err := u.ensureEmailAvailable(ctx, user, *user.PendingEmail); err != nil {
+				return err
+			}
+			user.Email = *user.PendingEmail
+			user.PendingEmail = nil
+			user.VerifiedAt = &now
+		case strings.EqualFold(user.Email, claims.Email):
+			if user.VerifiedAt != nil {
+				return nil
+			}
+			user.VerifiedAt = &now
+		default:
+			return invalid
+		}
+
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to verify user email")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, txError(err)
+	}
+
+	return converter.UserToResponse(user), nil
+}
+
+// ResendVerification sends another link to the pending email, or else to
+// the user's email while it is unverified.
+func (u *UserUsecase) ResendVerification(ctx context.Context, request *domain.ResendVerificationRequest) error {
+	err := u.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := u.UserRepo.FindByID(ctx, request.ID)
+		if err != nil {
+			u.Log.WithError(err).Error("Failed to found user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+
+		email := user.Email
+		if user.PendingEmail != nil {
+			email = *user.PendingEmail
+		} else if user.VerifiedAt != nil {
+			return util.NewCustomError(int(util.ErrConflictCode), "Email is already verified")
+		}
+
+		now := time.Now()
+		if user.VerificationSentAt != nil && now.Sub(*user.VerificationSentAt) < verificationResendInterval {
+			return util.NewCustomError(int(util.ErrTooManyRequestsCode), "Verification email was sent recently, try again later")
+		}
+		user.VerificationSentAt = &now
+
+		if err := u.UserRepo.Update(ctx, user); err != nil {
+			u.Log.WithError(err).Error("Failed to update user")
+			return util.NewCustomError(int(util.ErrInternalServerErrorCode), err.Error())
+		}
+		return u.sendVerification(ctx, user, email)
+	})
+	if err != nil {
+		return txError(err)
+	}
+
+	return nil
+}
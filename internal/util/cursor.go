@@ -0,0 +1,47 @@
+package util
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type CursorToken struct {
+	Sort     string `json:"s"`
+	Values   []any  `json:"v"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// CursorCodec turns keyset positions into opaque tokens. Tokens are signed
+// so clients cannot forge positions or tamper with the sort they belong to.
+type CursorCodec struct {
+	Secret []byte
+}
+
+func NewCursorCodec(secret string) *CursorCodec {
+	return &CursorCodec{Secret: []byte(secret)}
+}
+
+func (c *CursorCodec) Encode(token *CursorToken) (string, error) {
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return seal(c.Secret, raw), nil
+}
+
+func (c *CursorCodec) Decode(cursor string) (*CursorToken, error) {
+	raw, ok := unseal(c.Secret, cursor)
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	var token CursorToken
+	if err := json.Unmarshal(raw, &token); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &token, nil
+}
@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/sirupsen/logrus"
@@ -14,6 +15,10 @@ const (
 	ErrConflictCode
 	ErrUnauthorizedCode
 	ErrBadRequestCode
+	ErrForbiddenCode
+	ErrPreconditionFailedCode
+	ErrPreconditionRequiredCode
+	ErrTooManyRequestsCode
 )
 
 type CustomError struct {
@@ -38,8 +43,32 @@ var (
 	ErrConflict            = &CustomError{Code: ErrConflictCode}
 	ErrUnauthorized        = &CustomError{Code: ErrUnauthorizedCode}
 	ErrBadRequest          = &CustomError{Code: ErrUnauthorizedCode}
+	ErrForbidden           = &CustomError{Code: ErrForbiddenCode}
+	ErrPreconditionFailed  = &CustomError{Code: ErrPreconditionFailedCode}
+
+	// ErrVersionConflict is returned by repositories when a row changed
+	// between reading it and writing it back.
+	ErrVersionConflict = errors.New("version conflict")
 )
 
+// ItemError ties a failure to the position of the item in a bulk request.
+type ItemError struct {
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+func NewItemError(index int, err error) *ItemError {
+	return &ItemError{Index: index, Err: err}
+}
+
 func GetStatusCode(err error) int {
 	if err == nil {
 		return http.StatusOK
@@ -47,8 +76,9 @@ func GetStatusCode(err error) int {
 
 	logrus.Error(err)
 
-	switch e := err.(type) {
-	case *CustomError:
+	var e *CustomError
+	switch {
+	case errors.As(err, &e):
 		switch e.Code {
 		case ErrInternalServerErrorCode:
 			return http.StatusInternalServerError
@@ -60,6 +90,14 @@ func GetStatusCode(err error) int {
 			return http.StatusUnauthorized
 		case ErrBadRequestCode:
 			return http.StatusBadRequest
+		case ErrForbiddenCode:
+			return http.StatusForbidden
+		case ErrPreconditionFailedCode:
+			return http.StatusPreconditionFailed
+		case ErrPreconditionRequiredCode:
+			return http.StatusPreconditionRequired
+		case ErrTooManyRequestsCode:
+			return http.StatusTooManyRequests
 		default:
 			return http.StatusInternalServerError
 		}
@@ -0,0 +1,308 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRRule = errors.New("invalid recurrence rule")
+
+// maxRRulePeriods bounds how far a rule is walked, so rules whose filters
+// never match cannot loop forever.
+const maxRRulePeriods = 50000
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+type RRuleDay struct {
+	Weekday time.Weekday
+	// N selects the nth weekday of the month, negative counting from the
+	// end. Zero means every matching weekday.
+	N int
+}
+
+// RRule is the subset of RFC 5545 recurrence rules the API accepts: FREQ of
+// DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY, BYMONTHDAY, COUNT and UNTIL.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      *time.Time
+	ByDay      []RRuleDay
+	ByMonthDay []int
+}
+
+func ParseRRule(rule string) (*RRule, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	r := &RRule{Interval: 1}
+
+	for _, part := range strings.Split(rule, ";") {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch value = strings.ToUpper(value); value {
+			case "DAILY", "WEEKLY", "MONTHLY":
+				r.Freq = value
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRRule, value)
+			}
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return nil, fmt.Errorf("%w: invalid INTERVAL %q", ErrInvalidRRule, value)
+			}
+			r.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: invalid COUNT %q", ErrInvalidRRule, value)
+			}
+			r.Count = count
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = &until
+		case "BYDAY":
+			for _, day := range strings.Split(strings.ToUpper(value), ",") {
+				parsed, err := parseRRuleDay(day)
+				if err != nil {
+					return nil, err
+				}
+				r.ByDay = append(r.ByDay, parsed)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return nil, fmt.Errorf("%w: invalid BYMONTHDAY %q", ErrInvalidRRule, day)
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRRule, key)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot both be set", ErrInvalidRRule)
+	}
+	for _, day := range r.ByDay {
+		if day.N != 0 && r.Freq != "MONTHLY" {
+			return nil, fmt.Errorf("%w: numbered BYDAY is only valid with FREQ=MONTHLY", ErrInvalidRRule)
+		}
+	}
+	if len(r.ByMonthDay) > 0 && r.Freq == "WEEKLY" {
+		return nil, fmt.Errorf("%w: BYMONTHDAY is not valid with FREQ=WEEKLY", ErrInvalidRRule)
+	}
+
+	return r, nil
+}
+
+func parseRRuleDay(day string) (RRuleDay, error) {
+	if len(day) < 2 {
+		return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, day)
+	}
+
+	weekday, ok := rruleWeekdays[day[len(day)-2:]]
+	if !ok {
+		return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, day)
+	}
+
+	var n int
+	if prefix := day[:len(day)-2]; prefix != "" {
+		var err error
+		n, err = strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -5 || n > 5 {
+			return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, day)
+		}
+	}
+
+	return RRuleDay{Weekday: weekday, N: n}, nil
+}
+
+func parseRRuleTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: invalid UNTIL %q", ErrInvalidRRule, value)
+}
+
+// After returns the first occurrence of the series starting at dtstart that
+// falls strictly after t.
+func (r *RRule) After(dtstart, t time.Time) (time.Time, bool) {
+	var (
+		next  time.Time
+		found bool
+	)
+	r.iterate(dtstart, func(occurrence time.Time) bool {
+		if occurrence.After(t) {
+			next, found = occurrence, true
+			return false
+		}
+		return true
+	})
+	return next, found
+}
+
+// Between returns at most limit occurrences in [from, to).
+func (r *RRule) Between(dtstart, from, to time.Time, limit int) []time.Time {
+	var occurrences []time.Time
+	r.iterate(dtstart, func(occurrence time.Time) bool {
+		if !occurrence.Before(to) {
+			return false
+		}
+		if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+		return len(occurrences) < limit
+	})
+	return occurrences
+}
+
+// iterate walks the series in order, calling fn until it returns false or the
+// series ends. dtstart is always the first occurrence, as in RFC 5545.
+func (r *RRule) iterate(dtstart time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	emit := func(t time.Time) bool {
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		if r.Count > 0 && emitted >= r.Count {
+			return false
+		}
+		emitted++
+		return fn(t)
+	}
+
+	if !emit(dtstart) {
+		return
+	}
+
+	for period := 0; period < maxRRulePeriods; period++ {
+		for _, candidate := range r.candidates(dtstart, period*r.Interval) {
+			if !candidate.After(dtstart) {
+				continue
+			}
+			if !emit(candidate) {
+				return
+			}
+		}
+	}
+}
+
+// candidates lists the occurrences in the period offset periods after the
+// one containing dtstart, in chronological order.
+func (r *RRule) candidates(dtstart time.Time, offset int) []time.Time {
+	y, m, d := dtstart.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location())
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case "DAILY":
+		day := at(y, m, d+offset)
+		if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+	case "WEEKLY":
+		// Weeks start on Monday, the RFC 5545 default for WKST.
+		monday := at(y, m, d-(int(dtstart.Weekday())+6)%7+offset*7)
+		if len(r.ByDay) == 0 {
+			days = append(days, monday.AddDate(0, 0, (int(dtstart.Weekday())+6)%7))
+			break
+		}
+		for i := 0; i < 7; i++ {
+			day := monday.AddDate(0, 0, i)
+			if r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+	case "MONTHLY":
+		first := at(y, m+time.Month(offset), 1)
+		year, month := first.Year(), first.Month()
+		length := at(year, month+1, 0).Day()
+
+		for day := 1; day <= length; day++ {
+			candidate := at(year, month, day)
+			switch {
+			case len(r.ByDay) == 0 && len(r.ByMonthDay) == 0:
+				if day == d {
+					days = append(days, candidate)
+				}
+			case r.matchesMonthDay(candidate) && r.matchesNthWeekday(candidate, length):
+				days = append(days, candidate)
+			}
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+func (r *RRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesNthWeekday(t time.Time, monthLength int) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day.Weekday != t.Weekday() {
+			continue
+		}
+		switch {
+		case day.N == 0:
+			return true
+		case day.N > 0 && (t.Day()-1)/7+1 == day.N:
+			return true
+		case day.N < 0 && (monthLength-t.Day())/7+1 == -day.N:
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RRule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	length := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, day := range r.ByMonthDay {
+		if day == t.Day() || (day < 0 && length+day+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package util
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		want    []time.Time
+	}{
+		{
+			name:    "last Friday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: date(2024, time.January, 26, 9),
+			want: []time.Time{
+				date(2024, time.January, 26, 9),
+				date(2024, time.February, 23, 9),
+				date(2024, time.March, 29, 9),
+				date(2024, time.April, 26, 9),
+				date(2024, time.May, 31, 9),
+			},
+		},
+		{
+			name:    "second Tuesday of the month",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU",
+			dtstart: date(2024, time.January, 9, 9),
+			want: []time.Time{
+				date(2024, time.January, 9, 9),
+				date(2024, time.February, 13, 9),
+				date(2024, time.March, 12, 9),
+			},
+		},
+		{
+			name:    "BYMONTHDAY=31 skips short months",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=31",
+			dtstart: date(2024, time.January, 31, 9),
+			want: []time.Time{
+				date(2024, time.January, 31, 9),
+				date(2024, time.March, 31, 9),
+				date(2024, time.May, 31, 9),
+				date(2024, time.July, 31, 9),
+				date(2024, time.August, 31, 9),
+			},
+		},
+		{
+			name:    "BYMONTHDAY=-1 is the last day of every month",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=-1",
+			dtstart: date(2023, time.December, 31, 9),
+			want: []time.Time{
+				date(2023, time.December, 31, 9),
+				date(2024, time.January, 31, 9),
+				date(2024, time.February, 29, 9),
+				date(2024, time.March, 31, 9),
+				date(2024, time.April, 30, 9),
+			},
+		},
+		{
+			name:    "monthly on the 31st without BYMONTHDAY skips short months",
+			rule:    "FREQ=MONTHLY",
+			dtstart: date(2024, time.January, 31, 9),
+			want: []time.Time{
+				date(2024, time.January, 31, 9),
+				date(2024, time.March, 31, 9),
+				date(2024, time.May, 31, 9),
+			},
+		},
+		{
+			name:    "every other week on Monday and Wednesday, starting on a Thursday",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			dtstart: date(2024, time.January, 4, 9),
+			want: []time.Time{
+				date(2024, time.January, 4, 9),
+				date(2024, time.January, 15, 9),
+				date(2024, time.January, 17, 9),
+				date(2024, time.January, 29, 9),
+				date(2024, time.January, 31, 9),
+			},
+		},
+		{
+			name:    "weekly without BYDAY keeps the weekday of dtstart",
+			rule:    "FREQ=WEEKLY",
+			dtstart: date(2024, time.January, 4, 9),
+			want: []time.Time{
+				date(2024, time.January, 4, 9),
+				date(2024, time.January, 11, 9),
+				date(2024, time.January, 18, 9),
+			},
+		},
+		{
+			name:    "daily on weekdays",
+			rule:    "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR",
+			dtstart: date(2024, time.January, 5, 9),
+			want: []time.Time{
+				date(2024, time.January, 5, 9),
+				date(2024, time.January, 8, 9),
+				date(2024, time.January, 9, 9),
+			},
+		},
+		{
+			name:    "COUNT includes dtstart",
+			rule:    "FREQ=DAILY;COUNT=3",
+			dtstart: date(2024, time.January, 1, 9),
+			want: []time.Time{
+				date(2024, time.January, 1, 9),
+				date(2024, time.January, 2, 9),
+				date(2024, time.January, 3, 9),
+			},
+		},
+		{
+			name:    "UNTIL is inclusive",
+			rule:    "FREQ=DAILY;UNTIL=20240103T090000Z",
+			dtstart: date(2024, time.January, 1, 9),
+			want: []time.Time{
+				date(2024, time.January, 1, 9),
+				date(2024, time.January, 2, 9),
+				date(2024, time.January, 3, 9),
+			},
+		},
+		{
+			name:    "UNTIL before the time of day of the last day",
+			rule:    "FREQ=DAILY;UNTIL=20240103T090000Z",
+			dtstart: date(2024, time.January, 1, 10),
+			want: []time.Time{
+				date(2024, time.January, 1, 10),
+				date(2024, time.January, 2, 10),
+			},
+		},
+		{
+			name:    "COUNT counts occurrences, not periods",
+			rule:    "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=4",
+			dtstart: date(2024, time.January, 1, 9),
+			want: []time.Time{
+				date(2024, time.January, 1, 9),
+				date(2024, time.January, 5, 9),
+				date(2024, time.January, 8, 9),
+				date(2024, time.January, 12, 9),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q): %v", tt.rule, err)
+			}
+
+			// A bounded series is asked for one more, which it must not have.
+			limit := len(tt.want)
+			if rule.Count > 0 || rule.Until != nil {
+				limit++
+			}
+			got := rule.Between(tt.dtstart, tt.dtstart, tt.dtstart.AddDate(1, 0, 0), limit)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i := range tt.want {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("occurrence %d is %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRRuleAfter(t *testing.T) {
+	rule, err := ParseRRule("RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dtstart := date(2024, time.January, 26, 9)
+
+	if next, ok := rule.After(dtstart, dtstart); !ok || !next.Equal(date(2024, time.February, 23, 9)) {
+		t.Fatalf("After(dtstart) = %v, %v", next, ok)
+	}
+	if next, ok := rule.After(dtstart, date(2024, time.March, 1, 0)); !ok || !next.Equal(date(2024, time.March, 29, 9)) {
+		t.Fatalf("After(March 1) = %v, %v", next, ok)
+	}
+	if next, ok := rule.After(dtstart, date(2024, time.March, 29, 9)); ok {
+		t.Fatalf("After(last occurrence) = %v, want none", next)
+	}
+}
+
+func TestParseRRuleRejects(t *testing.T) {
+	for _, rule := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=YEARLY",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240103T090000Z",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;UNTIL=tomorrow",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;BYHOUR=9",
+		"FREQ=DAILY;COUNT",
+	} {
+		if _, err := ParseRRule(rule); !errors.Is(err, ErrInvalidRRule) {
+			t.Errorf("ParseRRule(%q) = %v, want %v", rule, err, ErrInvalidRRule)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSignedToken = errors.New("invalid or expired token")
+
+// seal encodes raw as base64url(raw).signature, the HMAC-SHA256 of the
+// encoded part under secret. Cursors and signed tokens are both sealed this
+// way.
+func seal(secret, raw []byte) string {
+	encoded := base64.RawURLEncoding.EncodeToString(raw)
+	return encoded + "." + hmacSignature(secret, encoded)
+}
+
+// unseal returns the bytes sealed in token, or false if it was not sealed
+// under secret.
+func unseal(secret []byte, token string) ([]byte, bool) {
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(hmacSignature(secret, encoded))) {
+		return nil, false
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+func hmacSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// TokenSigner issues self-contained tokens that carry a JSON payload and an
+// expiry, signed so clients cannot forge or alter them.
+type TokenSigner struct {
+	Secret []byte
+}
+
+func NewTokenSigner(secret string) *TokenSigner {
+	return &TokenSigner{Secret: []byte(secret)}
+}
+
+type signedEnvelope struct {
+	Payload   json.RawMessage `json:"p"`
+	ExpiresAt int64           `json:"e"`
+}
+
+func (s *TokenSigner) Sign(payload any, expiresAt time.Time) (string, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+	raw, err = json.Marshal(signedEnvelope{Payload: raw, ExpiresAt: expiresAt.Unix()})
+	if err != nil {
+		return "", err
+	}
+
+	return seal(s.Secret, raw), nil
+}
+
+// Verify checks token and decodes its payload into dst.
+func (s *TokenSigner) Verify(token string, dst any) error {
+	raw, ok := unseal(s.Secret, token)
+	if !ok {
+		return ErrInvalidSignedToken
+	}
+
+	var envelope signedEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return ErrInvalidSignedToken
+	}
+	if time.Now().Unix() >= envelope.ExpiresAt {
+		return ErrInvalidSignedToken
+	}
+
+	if err := json.Unmarshal(envelope.Payload, dst); err != nil {
+		return ErrInvalidSignedToken
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of RFC 6238 Appendix B, "12345678901234567890".
+var rfc6238Secret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+// The SHA1 test vectors of RFC 6238 Appendix B, cut to the last six digits
+// of the eight-digit codes there.
+func TestValidateTOTPVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, now, 0)
+		if !ok {
+			t.Errorf("code %s refused at %d", tt.code, tt.unix)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("code %s at %d has step %d, want %d", tt.code, tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+
+	for offset := int64(-2); offset <= 2; offset++ {
+		_, ok := ValidateTOTP(rfc6238Secret, totpCode(key, current+offset), now, 0)
+		if want := offset >= -totpSkew && offset <= totpSkew; ok != want {
+			t.Errorf("code of step %+d accepted = %v, want %v", offset, ok, want)
+		}
+	}
+}
+
+func TestValidateTOTPReplay(t *testing.T) {
+	key := []byte("12345678901234567890")
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	code := totpCode(key, current)
+
+	step, ok := ValidateTOTP(rfc6238Secret, code, now, 0)
+	if !ok || step != current {
+		t.Fatalf("first use refused")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, code, now, step); ok {
+		t.Fatal("the same code was accepted twice")
+	}
+	if _, ok := ValidateTOTP(rfc6238Secret, totpCode(key, current-1), now, step); ok {
+		t.Fatal("a code older than the last accepted one was accepted")
+	}
+	if next, ok := ValidateTOTP(rfc6238Secret, totpCode(key, current+1), now, step); !ok || next != current+1 {
+		t.Fatal("the code of the next step was refused")
+	}
+}
+
+func TestValidateTOTPRejects(t *testing.T) {
+	now := time.Unix(59, 0)
+	for name, input := range map[string][2]string{
+		"wrong code":     {rfc6238Secret, "287083"},
+		"eight digits":   {rfc6238Secret, "94287082"},
+		"short code":     {rfc6238Secret, "28708"},
+		"invalid secret": {"not base32!", "287082"},
+		"different seed": {totpEncoding.EncodeToString([]byte("09876543210987654321")), "287082"},
+	} {
+		if _, ok := ValidateTOTP(input[0], input[1], now, 0); ok {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, ok := ValidateTOTP(strings.ToLower(rfc6238Secret), "287082", now, 0); !ok {
+		t.Error("lowercase secret refused")
+	}
+}
@@ -0,0 +1,39 @@
+package workers
+
+import (
+	"context"
+	"go-todo-api/internal/usecase"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountWorker sends the emails users ask for about their account.
+type AccountWorker struct {
+	Log   *logrus.Logger
+	Users *usecase.UserUsecase
+}
+
+func NewAccountWorker(logger *logrus.Logger, users *usecase.UserUsecase) *AccountWorker {
+	return &AccountWorker{
+		Log:   logger,
+		Users: users,
+	}
+}
+
+func (w *AccountWorker) SendPasswordReset(job *work.Job) error {
+	email := job.ArgString("email")
+	if err := job.ArgError(); err != nil {
+		return err
+	}
+
+	if err := w.Users.SendPasswordReset(context.Background(), email, outboxID(job)); err != nil {
+		w.Log.WithError(err).Error("Failed to send password reset")
+		return err
+	}
+	return nil
+}
+
+func RegisterAccountJobs(workerPool *work.WorkerPool, w *AccountWorker) {
+	workerPool.Job(usecase.JobPasswordReset, w.SendPasswordReset)
+}
@@ -0,0 +1,36 @@
+package workers
+
+import (
+	"context"
+	"go-todo-api/internal/usecase"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+)
+
+type DigestWorker struct {
+	Log     *logrus.Logger
+	Digests *usecase.DigestUsecase
+}
+
+func NewDigestWorker(logger *logrus.Logger, digests *usecase.DigestUsecase) *DigestWorker {
+	return &DigestWorker{
+		Log:     logger,
+		Digests: digests,
+	}
+}
+
+func (w *DigestWorker) Sweep(job *work.Job) error {
+	if err := w.Digests.Sweep(context.Background()); err != nil {
+		w.Log.WithError(err).Error("Failed to send digests")
+		return err
+	}
+	return nil
+}
+
+// RegisterDigestJobs runs the digest sweep every 15 minutes. Each user is
+// only sent a digest once per slot, whatever the number of sweeps.
+func RegisterDigestJobs(workerPool *work.WorkerPool, w *DigestWorker) {
+	workerPool.Job(usecase.JobDigestSweep, w.Sweep)
+	workerPool.PeriodicallyEnqueue("0 */15 * * * *", usecase.JobDigestSweep)
+}
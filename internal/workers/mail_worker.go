@@ -1,22 +1,38 @@
 package workers
 
 import (
-	"go-todo-api/internal/config"
-	"log"
+	"context"
+	"go-todo-api/internal/mailer"
+	"go-todo-api/internal/usecase"
+	"math/rand"
+	"time"
 
 	"github.com/gocraft/work"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// A message that still fails after mailMaxFails attempts is moved to the
+	// dead letters. Retries back off from mailBaseBackoff up to
+	// mailMaxBackoff, so the attempts span roughly two hours.
+	mailMaxFails    = 8
+	mailBaseBackoff = 30 * time.Second
+	mailMaxBackoff  = time.Hour
+)
+
 type MailWorker struct {
-	Log    *logrus.Logger
-	Mailer *config.MailerConfig
+	Log         *logrus.Logger
+	Mailer      mailer.Mailer
+	DeadLetters *usecase.DeadLetterUsecase
+	Outbox      *usecase.OutboxUsecase
 }
 
-func NewMailWorker(logger *logrus.Logger, mailer *config.MailerConfig) *MailWorker {
+func NewMailWorker(logger *logrus.Logger, m mailer.Mailer, deadLetters *usecase.DeadLetterUsecase, outbox *usecase.OutboxUsecase) *MailWorker {
 	return &MailWorker{
-		Log:    logger,
-		Mailer: mailer,
+		Log:         logger,
+		Mailer:      m,
+		DeadLetters: deadLetters,
+		Outbox:      outbox,
 	}
 }
 
@@ -24,20 +40,69 @@ func (w *MailWorker) SendEmail(job *work.Job) error {
 	to := job.ArgString("to")
 	subject := job.ArgString("subject")
 	body := job.ArgString("body")
+	// Jobs enqueued before plain-text bodies existed have no "text" arg.
+	text, _ := job.Args["text"].(string)
 
 	if err := job.ArgError(); err != nil {
 		return err
 	}
 
-	if err := w.Mailer.SendMail(to, subject, body); err != nil {
-		log.Printf("Failed to send email to %s: %v", to, err)
+	// A copy published again after a relay crash must not send the email
+	// twice. Sending is not transactional, so the email is only recorded as
+	// sent afterwards.
+	ctx := context.Background()
+	sent, err := w.Outbox.Processed(ctx, outboxID(job))
+	if err != nil {
 		return err
 	}
+	if sent {
+		w.Log.Infof("Email to %s was already sent, skipping", to)
+		return nil
+	}
+
+	err = w.Mailer.Send(&mailer.Message{
+		To:      to,
+		Subject: subject,
+		HTML:    body,
+		Text:    text,
+	})
+	if err != nil {
+		w.Log.WithError(err).Warnf("Failed to send email to %s (attempt %d)", to, job.Fails+1)
+		if job.Fails+1 < mailMaxFails {
+			return err
+		}
+
+		// If the dead letter cannot be written either, the error reaches the
+		// pool, which keeps the job in its own dead set.
+		if err := w.DeadLetters.Record(ctx, job, err); err != nil {
+			w.Log.WithError(err).Errorf("Failed to record dead letter for email to %s", to)
+			return err
+		}
+		w.Log.Errorf("Gave up sending email to %s, moved to dead letters", to)
+		return nil
+	}
 
-	log.Printf("Email sent successfully to %s", to)
+	w.Log.Infof("Email sent successfully to %s", to)
+	if _, err := w.Outbox.MarkProcessed(ctx, outboxID(job)); err != nil {
+		w.Log.WithError(err).Warnf("Failed to record email to %s as sent", to)
+	}
 	return nil
 }
 
-func RegisterMailJobs(workerPool *work.WorkerPool) {
-	workerPool.Job("send_email", (*MailWorker).SendEmail)
+// mailBackoff doubles the delay after each failure, with up to 10% jitter so
+// messages that failed together do not retry together.
+func mailBackoff(job *work.Job) int64 {
+	backoff := mailMaxBackoff
+	if job.Fails < mailMaxFails {
+		backoff = min(mailBaseBackoff<<max(job.Fails-1, 0), mailMaxBackoff)
+	}
+	seconds := int64(backoff.Seconds())
+	return seconds + rand.Int63n(seconds/10+1)
+}
+
+func RegisterMailJobs(workerPool *work.WorkerPool, w *MailWorker) {
+	workerPool.JobWithOptions(usecase.JobSendEmail, work.JobOptions{
+		MaxFails: mailMaxFails,
+		Backoff:  mailBackoff,
+	}, w.SendEmail)
 }
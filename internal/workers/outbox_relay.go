@@ -0,0 +1,99 @@
+package workers
+
+import (
+	"context"
+	"go-todo-api/internal/usecase"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	outboxRetention     = 7 * 24 * time.Hour
+	outboxPruneInterval = time.Hour
+)
+
+// OutboxRelay polls the outbox and publishes committed messages to the job
+// queue. It runs outside the worker pool so that it keeps retrying while
+// Redis is unavailable.
+type OutboxRelay struct {
+	Log      *logrus.Logger
+	Outbox   *usecase.OutboxUsecase
+	Interval time.Duration
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func NewOutboxRelay(logger *logrus.Logger, outbox *usecase.OutboxUsecase, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		Log:      logger,
+		Outbox:   outbox,
+		Interval: interval,
+	}
+}
+
+func (r *OutboxRelay) Start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run()
+}
+
+// Stop waits for the pass in progress to finish.
+func (r *OutboxRelay) Stop() {
+	close(r.stop)
+	<-r.done
+}
+
+func (r *OutboxRelay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.Interval)
+	defer ticker.Stop()
+
+	var lastPrune time.Time
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+
+		r.drain()
+
+		if time.Since(lastPrune) >= outboxPruneInterval {
+			lastPrune = time.Now()
+			if _, err := r.Outbox.Prune(context.Background(), outboxRetention); err != nil {
+				r.Log.WithError(err).Error("Failed to prune outbox")
+			}
+		}
+	}
+}
+
+// drain publishes batches until the outbox has no due messages left.
+func (r *OutboxRelay) drain() {
+	for {
+		handled, err := r.Outbox.Relay(context.Background())
+		if err != nil {
+			r.Log.WithError(err).Error("Failed to relay outbox")
+			return
+		}
+		if handled < usecase.OutboxBatchSize {
+			return
+		}
+
+		select {
+		case <-r.stop:
+			return
+		default:
+		}
+	}
+}
+
+// outboxID returns the outbox message a job was published from, or "" for
+// jobs that did not go through the outbox.
+func outboxID(job *work.Job) string {
+	id, _ := job.Args["outbox_id"].(string)
+	return id
+}
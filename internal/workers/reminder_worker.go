@@ -0,0 +1,53 @@
+package workers
+
+import (
+	"context"
+	"go-todo-api/internal/usecase"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+)
+
+type ReminderWorker struct {
+	Log       *logrus.Logger
+	Reminders *usecase.ReminderUsecase
+}
+
+func NewReminderWorker(logger *logrus.Logger, reminders *usecase.ReminderUsecase) *ReminderWorker {
+	return &ReminderWorker{
+		Log:       logger,
+		Reminders: reminders,
+	}
+}
+
+func (w *ReminderWorker) SendReminder(job *work.Job) error {
+	reminderID := job.ArgInt64("reminder_id")
+	remindAt := job.ArgInt64("remind_at")
+
+	if err := job.ArgError(); err != nil {
+		return err
+	}
+
+	if err := w.Reminders.DeliverReminder(context.Background(), uint(reminderID), remindAt); err != nil {
+		w.Log.WithError(err).Errorf("Failed to deliver reminder %d", reminderID)
+		return err
+	}
+	return nil
+}
+
+func (w *ReminderWorker) Sweep(job *work.Job) error {
+	if err := w.Reminders.Sweep(context.Background()); err != nil {
+		w.Log.WithError(err).Error("Failed to sweep reminders")
+		return err
+	}
+	return nil
+}
+
+// RegisterReminderJobs registers the reminder jobs on workerPool and runs the
+// sweep every minute to catch reminders whose scheduled job was lost and to
+// send overdue notices.
+func RegisterReminderJobs(workerPool *work.WorkerPool, w *ReminderWorker) {
+	workerPool.Job(usecase.JobTodoReminder, w.SendReminder)
+	workerPool.Job(usecase.JobReminderSweep, w.Sweep)
+	workerPool.PeriodicallyEnqueue("0 * * * * *", usecase.JobReminderSweep)
+}
@@ -0,0 +1,44 @@
+package workers
+
+import (
+	"context"
+	"go-todo-api/internal/usecase"
+	"time"
+
+	"github.com/gocraft/work"
+	"github.com/sirupsen/logrus"
+)
+
+// Ended sessions are kept for a week before they are deleted together with
+// their refresh tokens.
+const sessionRetention = 7 * 24 * time.Hour
+
+type SessionWorker struct {
+	Log   *logrus.Logger
+	Users *usecase.UserUsecase
+}
+
+func NewSessionWorker(logger *logrus.Logger, users *usecase.UserUsecase) *SessionWorker {
+	return &SessionWorker{
+		Log:   logger,
+		Users: users,
+	}
+}
+
+func (w *SessionWorker) Prune(job *work.Job) error {
+	pruned, err := w.Users.PruneSessions(context.Background(), sessionRetention)
+	if err != nil {
+		w.Log.WithError(err).Error("Failed to prune sessions")
+		return err
+	}
+	if pruned > 0 {
+		w.Log.Infof("Pruned %d ended sessions", pruned)
+	}
+	return nil
+}
+
+// RegisterSessionJobs prunes ended sessions daily.
+func RegisterSessionJobs(workerPool *work.WorkerPool, w *SessionWorker) {
+	workerPool.Job(usecase.JobSessionPrune, w.Prune)
+	workerPool.PeriodicallyEnqueue("0 30 3 * * *", usecase.JobSessionPrune)
+}